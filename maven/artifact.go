@@ -0,0 +1,362 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+// packagingExtensions maps POM packaging to the extension of the artifact it produces.
+var packagingExtensions = map[string]string{
+	"":             "jar",
+	"bundle":       "jar",
+	"ear":          "ear",
+	"ejb":          "jar",
+	"jar":          "jar",
+	"maven-plugin": "jar",
+	"war":          "war",
+}
+
+// ArtifactPattern returns the pattern, relative to the module, of the artifact built from a POM.  The pattern is the
+// exact file name when the finalName can be interpolated, and a glob constrained by packaging and classifier otherwise.
+// The classifier defaults to the one the spring-boot-maven-plugin repackages the application with.  Returns false if
+// the packaging does not produce an application artifact.
+func ArtifactPattern(pom POM, classifier string) (string, bool) {
+	extension, ok := packagingExtensions[pom.Packaging]
+	if !ok {
+		return "", false
+	}
+
+	name := pom.FinalName
+	if name == "" {
+		name = "${project.artifactId}-${project.version}"
+	}
+	name = pom.Interpolate(name)
+
+	if strings.Contains(name, "${") {
+		name = "*"
+	}
+
+	if classifier == "" {
+		classifier = springBootClassifier(pom)
+	}
+	if classifier != "" {
+		name = fmt.Sprintf("%s-%s", name, classifier)
+	}
+
+	return filepath.Join("target", fmt.Sprintf("%s.%s", name, extension)), true
+}
+
+// springBootClassifier returns the classifier of the executable artifact repackaged by the spring-boot-maven-plugin.
+func springBootClassifier(pom POM) string {
+	plugin, ok := pom.Plugin("spring-boot-maven-plugin")
+	if !ok {
+		return ""
+	}
+
+	for _, e := range plugin.Executions {
+		if e.Configuration.Classifier != "" {
+			return e.Configuration.Classifier
+		}
+	}
+	return plugin.Configuration.Classifier
+}
+
+// ArtifactFileDetector is a libbs.InterestingFileDetector that breaks the tie between several interesting files
+// matching the artifact pattern with the name of the artifact derived from the POM: when an interesting file in the
+// same directory matches Name, the other files are not interesting.
+type ArtifactFileDetector struct {
+	Delegate libbs.InterestingFileDetector
+	Name     string
+}
+
+func (a ArtifactFileDetector) Interesting(path string) (bool, error) {
+	if ok, err := a.Delegate.Interesting(path); err != nil || !ok {
+		return ok, err
+	}
+
+	if ok, err := filepath.Match(a.Name, filepath.Base(path)); err != nil {
+		return false, fmt.Errorf("unable to match %s with %s\n%w", path, a.Name, err)
+	} else if ok {
+		return true, nil
+	}
+
+	named, err := filepath.Glob(filepath.Join(filepath.Dir(path), a.Name))
+	if err != nil {
+		return false, fmt.Errorf("unable to find files with %s\n%w", a.Name, err)
+	}
+
+	for _, n := range named {
+		if ok, err := a.Delegate.Interesting(n); err != nil {
+			return false, fmt.Errorf("unable to investigate %s\n%w", n, err)
+		} else if ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ArtifactSelector selects the application artifact of a build: the artifact of the project or of its configured or
+// located module, the artifacts of several modules laid out under named subdirectories of the application root, the
+// Quarkus fast-jar layout, or a native image.
+type ArtifactSelector struct {
+	ApplicationPath       string
+	Arguments             []string
+	ConfigurationResolver libpak.ConfigurationResolver
+	Logger                bard.Logger
+	POM                   POM
+	POMFile               string
+	Project               string
+}
+
+// ArtifactSelection is the application artifact selected for a build.
+type ArtifactSelection struct {
+
+	// Arguments are appended to the Maven arguments to build the artifact.
+	Arguments []string
+
+	// InterestingFileDetector selects the artifact among the files matching its pattern.
+	InterestingFileDetector libbs.InterestingFileDetector
+
+	// Module is the comma separated application modules, relative to the application root.
+	Module string
+
+	// ModuleConfigurationKey is the configuration key of the module that libbs.ArtifactResolver prefixes the pattern
+	// with, empty if the pattern is prefixed already.
+	ModuleConfigurationKey string
+
+	// ModuleSet is whether the modules are configured by the user rather than located.
+	ModuleSet bool
+
+	// Modules lays out the artifacts of several modules, if selected.
+	Modules *ModulesExecutor
+
+	// Native lays out a native image, if selected.
+	Native *NativeExecutor
+
+	// Processes are the processes that launch the artifact.
+	Processes []libcnb.Process
+
+	// Resolver resolves $BP_MAVEN_BUILT_ARTIFACT, defaulting to the pattern of the artifact.
+	Resolver libpak.ConfigurationResolver
+}
+
+// Executor returns delegate wrapped by the executor that lays out the artifact, if any.
+func (a ArtifactSelection) Executor(delegate effect.Executor) effect.Executor {
+	if a.Native != nil {
+		n := *a.Native
+		n.Delegate = delegate
+		return n
+	}
+
+	if a.Modules != nil {
+		m := *a.Modules
+		m.Delegate = delegate
+		return m
+	}
+
+	return delegate
+}
+
+func (a ArtifactSelector) Select() (ArtifactSelection, error) {
+	cr := a.ConfigurationResolver
+	s := ArtifactSelection{
+		InterestingFileDetector: libbs.JARInterestingFileDetector{},
+		ModuleConfigurationKey:  "BP_MAVEN_BUILT_MODULE",
+		Resolver:                cr,
+	}
+
+	s.Module, s.ModuleSet = cr.Resolve("BP_MAVEN_BUILT_MODULE")
+	if s.ModuleSet && a.Project != "." {
+		// modules are configured relative to $BP_MAVEN_PROJECT_PATH, otherwise relative to the application root as
+		// libbs defines them, and tracked relative to the application root
+		if _, ok := cr.Resolve("BP_MAVEN_PROJECT_PATH"); ok {
+			paths := ParseModules(s.Module)
+			for i, p := range paths {
+				paths[i] = filepath.Join(a.Project, p)
+			}
+			s.Module = strings.Join(paths, ",")
+		}
+		s.ModuleConfigurationKey = ""
+	}
+
+	if _, ok := cr.Resolve("BP_MAVEN_BUILT_ARTIFACT"); !ok {
+		var err error
+		if paths := ParseModules(s.Module); len(paths) > 1 {
+			err = a.modules(&s, paths)
+		} else {
+			err = a.module(&s)
+		}
+		if err != nil {
+			return ArtifactSelection{}, err
+		}
+	}
+
+	if cr.ResolveBool("BP_NATIVE_IMAGE") {
+		if err := a.native(&s); err != nil {
+			return ArtifactSelection{}, err
+		}
+	}
+
+	return s, nil
+}
+
+// modules selects the artifact of each of several modules, staged and restored to a subdirectory named after the
+// module.
+func (a ArtifactSelector) modules(s *ArtifactSelection, paths []string) error {
+	classifier, _ := a.ConfigurationResolver.Resolve("BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER")
+	defaultPattern, _ := a.ConfigurationResolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")
+
+	modules, err := NewModules(a.ApplicationPath, paths, classifier, defaultPattern)
+	if err != nil {
+		return fmt.Errorf("unable to resolve modules\n%w", err)
+	}
+
+	s.Module = strings.Join(paths, ",")
+	s.ModuleConfigurationKey = ""
+	s.Resolver = withDefault(a.ConfigurationResolver, "BP_MAVEN_BUILT_ARTIFACT", filepath.Join(ModulesDirectory, "*"))
+	s.Modules = &ModulesExecutor{
+		ApplicationPath:         a.ApplicationPath,
+		InterestingFileDetector: libbs.JARInterestingFileDetector{},
+		Modules:                 modules,
+	}
+
+	for _, m := range modules {
+		if filepath.Ext(m.Artifact) == ".jar" {
+			s.Processes = append(s.Processes, libcnb.Process{
+				Type:    m.Name,
+				Command: fmt.Sprintf("java -jar %s", filepath.Join(a.ApplicationPath, m.Name, "*.jar")),
+			})
+		}
+	}
+
+	return nil
+}
+
+// module selects the artifact of the project, or of its configured or located module.
+func (a ArtifactSelector) module(s *ArtifactSelection) error {
+	cr := a.ConfigurationResolver
+	classifier, _ := cr.Resolve("BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER")
+	defaultPattern, _ := cr.Resolve("BP_MAVEN_BUILT_ARTIFACT")
+
+	// modules are only prefixed to the default pattern by libbs.ArtifactResolver when set by the user in a project
+	// at the application root
+	if s.ModuleSet && a.Project != "." {
+		s.Resolver = withDefault(cr, "BP_MAVEN_BUILT_ARTIFACT", filepath.Join(s.Module, defaultPattern))
+	} else if !s.ModuleSet {
+		if a.Project != "." {
+			s.Resolver = withDefault(cr, "BP_MAVEN_BUILT_ARTIFACT", filepath.Join(a.Project, defaultPattern))
+		}
+
+		located, err := ApplicationModules(a.ApplicationPath, a.POMFile)
+		if err != nil {
+			a.Logger.Bodyf("WARNING: unable to locate application module\n%s", err)
+		}
+
+		if len(located) > 1 {
+			return fmt.Errorf("unable to locate single application module, candidates: %s. "+
+				"Set $BP_MAVEN_BUILT_MODULE to select one", located)
+		} else if len(located) == 1 && located[0] != a.Project {
+			a.Logger.Bodyf("Located application module %s", located[0])
+			s.Module = located[0]
+			s.Resolver = withDefault(cr, "BP_MAVEN_BUILT_ARTIFACT", filepath.Join(s.Module, defaultPattern))
+		}
+	}
+
+	dir, pom := a.modulePOM(s.Module)
+
+	if framework, _ := pom.Framework(); framework == FrameworkQuarkus && classifier == "" {
+		// the fast-jar layout is a directory rather than a single artifact, restored to the application root
+		a.Logger.Bodyf("Selecting the Quarkus application in %s", filepath.Join(dir, "target"))
+		s.Resolver = withDefault(cr, "BP_MAVEN_BUILT_ARTIFACT", QuarkusArtifactPattern(pom, dir))
+		s.ModuleConfigurationKey = ""
+	} else if pattern, ok := ArtifactPattern(pom, classifier); ok && pom.ArtifactID != "" {
+		// the artifact pattern may match the other artifacts of the module, the derived name breaks the tie
+		s.InterestingFileDetector = ArtifactFileDetector{Delegate: s.InterestingFileDetector, Name: filepath.Base(pattern)}
+	}
+
+	return nil
+}
+
+// native selects the native image built by the native profile of the project or module.
+func (a ArtifactSelector) native(s *ArtifactSelection) error {
+	if s.Modules != nil {
+		return fmt.Errorf("unable to build native images of multiple modules %s", s.Module)
+	}
+
+	dir, pom := a.modulePOM(s.Module)
+
+	image, ok := NativeImage(pom)
+	if !ok {
+		a.Logger.Bodyf("WARNING: $BP_NATIVE_IMAGE is set, but %s has no %s profile with the native-maven-plugin",
+			filepath.Join(dir, "pom.xml"), NativeProfile)
+		return nil
+	}
+
+	a.Logger.Bodyf("Building native image %s", filepath.Join(dir, image))
+	if !contains(a.Arguments, []string{"-P" + NativeProfile}) {
+		s.Arguments = append(s.Arguments, "-P"+NativeProfile)
+	}
+	if !contains(a.Arguments, []string{"native:compile"}) {
+		s.Arguments = append(s.Arguments, "native:compile")
+	}
+
+	if _, ok := a.ConfigurationResolver.Resolve("BP_MAVEN_BUILT_ARTIFACT"); ok {
+		return nil
+	}
+
+	// the native image replaces the jar, and is restored to the application root
+	s.Native = &NativeExecutor{
+		ApplicationPath: a.ApplicationPath,
+		Archive:         filepath.Join(NativeDirectory, fmt.Sprintf("%s.zip", filepath.Base(image))),
+		Executable:      filepath.Join(dir, image),
+	}
+	s.Resolver = withDefault(a.ConfigurationResolver, "BP_MAVEN_BUILT_ARTIFACT", s.Native.Archive)
+	s.InterestingFileDetector = libbs.JARInterestingFileDetector{}
+	s.ModuleConfigurationKey = ""
+
+	executable := filepath.Join(a.ApplicationPath, filepath.Base(image))
+	s.Processes = append(s.Processes,
+		libcnb.Process{Type: "native-image", Command: executable},
+		libcnb.Process{Type: "web", Command: executable, Default: true},
+	)
+
+	return nil
+}
+
+// modulePOM returns the directory, relative to the application root, and the POM of the application module, the POM
+// of the project if there is no module.
+func (a ArtifactSelector) modulePOM(module string) (string, POM) {
+	if module == "" || module == a.Project {
+		return a.Project, a.POM
+	}
+
+	pom, err := ReadPOM(filepath.Join(a.ApplicationPath, module, "pom.xml"))
+	if err != nil {
+		a.Logger.Bodyf("WARNING: unable to read module POM, using defaults\n%s", err)
+	}
+	return module, pom
+}
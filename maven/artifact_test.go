@@ -0,0 +1,303 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testArtifact(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "artifact")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(path, "target"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, "target", "test-artifact-1.0.0.jar"), []byte{}, 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, "target", "test-artifact-1.0.0-plain.jar"), []byte{}, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	context("ArtifactPattern", func() {
+		it("uses artifactId and version by default", func() {
+			pattern, ok := maven.ArtifactPattern(maven.POM{ArtifactID: "test-artifact", Version: "1.0.0"}, "")
+			Expect(ok).To(BeTrue())
+			Expect(pattern).To(Equal("target/test-artifact-1.0.0.jar"))
+		})
+
+		it("uses finalName, packaging and classifier", func() {
+			pattern, ok := maven.ArtifactPattern(maven.POM{
+				ArtifactID: "test-artifact",
+				Packaging:  "war",
+				FinalName:  "test-name",
+			}, "test-classifier")
+			Expect(ok).To(BeTrue())
+			Expect(pattern).To(Equal("target/test-name-test-classifier.war"))
+		})
+
+		it("uses glob when finalName cannot be interpolated", func() {
+			pattern, ok := maven.ArtifactPattern(maven.POM{ArtifactID: "test-artifact", Version: "${revision}"}, "")
+			Expect(ok).To(BeTrue())
+			Expect(pattern).To(Equal("target/*.jar"))
+		})
+
+		it("uses the classifier of the spring-boot-maven-plugin", func() {
+			pattern, ok := maven.ArtifactPattern(maven.POM{
+				ArtifactID: "test-artifact",
+				Version:    "1.0.0",
+				Plugins: []maven.Plugin{
+					{ArtifactID: "spring-boot-maven-plugin", Configuration: maven.Configuration{Classifier: "exec"}},
+				},
+			}, "")
+			Expect(ok).To(BeTrue())
+			Expect(pattern).To(Equal("target/test-artifact-1.0.0-exec.jar"))
+		})
+
+		it("does not return a pattern for pom packaging", func() {
+			_, ok := maven.ArtifactPattern(maven.POM{Packaging: "pom"}, "")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	context("ArtifactFileDetector", func() {
+		var detector maven.ArtifactFileDetector
+
+		it.Before(func() {
+			detector = maven.ArtifactFileDetector{
+				Delegate: libbs.AlwaysInterestingFileDetector{},
+				Name:     "test-artifact-1.0.0.jar",
+			}
+		})
+
+		it("breaks the tie with the derived artifact", func() {
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0.jar"))).To(BeTrue())
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0-plain.jar"))).To(BeFalse())
+		})
+
+		it("falls back to the delegate without the derived artifact", func() {
+			detector.Name = "other-artifact-1.0.0.jar"
+
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0.jar"))).To(BeTrue())
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0-plain.jar"))).To(BeTrue())
+		})
+
+		it("prefers an executable artifact to a derived artifact that is not", func() {
+			jar := func(file string, manifest string) {
+				out, err := os.Create(filepath.Join(path, "target", file))
+				Expect(err).NotTo(HaveOccurred())
+				defer out.Close()
+
+				z := zip.NewWriter(out)
+				w, err := z.Create("META-INF/MANIFEST.MF")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = w.Write([]byte(manifest))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(z.Close()).To(Succeed())
+			}
+			jar("test-artifact-1.0.0.jar", "Manifest-Version: 1.0\n")
+			jar("test-artifact-1.0.0-exec.jar", "Manifest-Version: 1.0\nMain-Class: org.example.Main\n")
+
+			detector.Delegate = libbs.JARInterestingFileDetector{}
+
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0.jar"))).To(BeFalse())
+			Expect(detector.Interesting(filepath.Join(path, "target", "test-artifact-1.0.0-exec.jar"))).To(BeTrue())
+		})
+	})
+
+	context("ArtifactSelector", func() {
+		var selector maven.ArtifactSelector
+
+		it.Before(func() {
+			selector = maven.ArtifactSelector{
+				ApplicationPath: path,
+				ConfigurationResolver: libpak.ConfigurationResolver{Configurations: []libpak.BuildpackConfiguration{
+					{Name: "BP_MAVEN_BUILT_ARTIFACT", Default: "target/*.[ejw]ar"},
+				}},
+				POM:     maven.POM{ArtifactID: "test-artifact", Version: "1.0.0"},
+				POMFile: "pom.xml",
+				Project: ".",
+			}
+		})
+
+		// pom writes a POM with the given content to the module directory
+		pom := func(module string, content string) {
+			Expect(os.MkdirAll(filepath.Join(path, module), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, module, "pom.xml"), []byte(content), 0644)).To(Succeed())
+		}
+
+		it("selects the artifact of the project", func() {
+			s, err := selector.Select()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Module).To(BeEmpty())
+			Expect(s.ModuleConfigurationKey).To(Equal("BP_MAVEN_BUILT_MODULE"))
+			Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("target/*.[ejw]ar"))
+			Expect(s.InterestingFileDetector).To(Equal(maven.ArtifactFileDetector{
+				Delegate: libbs.JARInterestingFileDetector{},
+				Name:     "test-artifact-1.0.0.jar",
+			}))
+			Expect(s.Executor(&FakeExecutor{})).To(Equal(&FakeExecutor{}))
+		})
+
+		it("locates the application module", func() {
+			pom(".", "<project><modules><module>lib</module><module>app</module></modules></project>")
+			pom("lib", "<project><artifactId>lib</artifactId></project>")
+			pom("app", "<project><artifactId>app</artifactId><version>1.0.0</version><packaging>war</packaging></project>")
+
+			s, err := selector.Select()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Module).To(Equal("app"))
+			Expect(s.ModuleSet).To(BeFalse())
+			Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("app/target/*.[ejw]ar"))
+			Expect(s.InterestingFileDetector.(maven.ArtifactFileDetector).Name).To(Equal("app-1.0.0.war"))
+		})
+
+		context("BP_MAVEN_BUILT_MODULE is set", func() {
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_MODULE")).To(Succeed())
+			})
+
+			it("selects the artifact of the module relative to the project path", func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "app")).To(Succeed())
+				Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", "services")).To(Succeed())
+				defer os.Unsetenv("BP_MAVEN_PROJECT_PATH")
+				pom("services/app", "<project><artifactId>app</artifactId><version>1.0.0</version></project>")
+				selector.Project = "services"
+
+				s, err := selector.Select()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(s.Module).To(Equal("services/app"))
+				Expect(s.ModuleSet).To(BeTrue())
+				Expect(s.ModuleConfigurationKey).To(BeEmpty())
+				Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("services/app/target/*.[ejw]ar"))
+				Expect(s.InterestingFileDetector.(maven.ArtifactFileDetector).Name).To(Equal("app-1.0.0.jar"))
+			})
+
+			it("selects the artifact of the module relative to the application root", func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "services/app")).To(Succeed())
+				pom("services/app", "<project><artifactId>app</artifactId><version>1.0.0</version></project>")
+				selector.Project = "services"
+
+				s, err := selector.Select()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(s.Module).To(Equal("services/app"))
+				Expect(s.ModuleConfigurationKey).To(BeEmpty())
+				Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("services/app/target/*.[ejw]ar"))
+			})
+
+			it("lays out the artifacts of several modules", func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "lib,app")).To(Succeed())
+				pom("lib", "<project><artifactId>lib</artifactId><version>1.0.0</version></project>")
+
+				s, err := selector.Select()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(s.ModuleConfigurationKey).To(BeEmpty())
+				Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("target/paketo-modules/*"))
+				Expect(s.Processes).To(Equal([]libcnb.Process{
+					{Type: "lib", Command: fmt.Sprintf("java -jar %s", filepath.Join(path, "lib", "*.jar"))},
+				}))
+				Expect(s.Executor(&FakeExecutor{})).To(Equal(maven.ModulesExecutor{
+					ApplicationPath:         path,
+					Delegate:                &FakeExecutor{},
+					InterestingFileDetector: libbs.JARInterestingFileDetector{},
+					Modules: []maven.Module{
+						{Name: "lib", Path: "lib", Pattern: "lib/target/*.[ejw]ar", Artifact: "lib-1.0.0.jar"},
+						{Name: "app", Path: "app", Pattern: "app/target/*.[ejw]ar"},
+					},
+				}))
+			})
+		})
+
+		it("selects the Quarkus fast-jar layout", func() {
+			selector.POM.Plugins = []maven.Plugin{{GroupID: "io.quarkus", ArtifactID: "quarkus-maven-plugin"}}
+
+			s, err := selector.Select()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.ModuleConfigurationKey).To(BeEmpty())
+			Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal(maven.QuarkusArtifactPattern(selector.POM, ".")))
+			Expect(s.InterestingFileDetector).To(Equal(libbs.JARInterestingFileDetector{}))
+		})
+
+		context("BP_NATIVE_IMAGE is set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_NATIVE_IMAGE", "true")).To(Succeed())
+				selector.Arguments = []string{"package"}
+				selector.POM.Profiles = []maven.Profile{
+					{ID: "native", Plugins: []maven.Plugin{{ArtifactID: "native-maven-plugin"}}},
+				}
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_NATIVE_IMAGE")).To(Succeed())
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_MODULE")).To(Succeed())
+			})
+
+			it("selects the native image", func() {
+				s, err := selector.Select()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(s.Arguments).To(Equal([]string{"-Pnative", "native:compile"}))
+				Expect(s.Resolver.Resolve("BP_MAVEN_BUILT_ARTIFACT")).To(Equal("target/paketo-native/test-artifact.zip"))
+				Expect(s.Executor(&FakeExecutor{})).To(Equal(maven.NativeExecutor{
+					ApplicationPath: path,
+					Archive:         "target/paketo-native/test-artifact.zip",
+					Delegate:        &FakeExecutor{},
+					Executable:      "target/test-artifact",
+				}))
+				Expect(s.Processes).To(Equal([]libcnb.Process{
+					{Type: "native-image", Command: filepath.Join(path, "test-artifact")},
+					{Type: "web", Command: filepath.Join(path, "test-artifact"), Default: true},
+				}))
+			})
+
+			it("fails with several modules", func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "lib,app")).To(Succeed())
+
+				_, err := selector.Select()
+				Expect(err).To(MatchError("unable to build native images of multiple modules lib,app"))
+			})
+		})
+	})
+}
@@ -24,8 +24,9 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
-	"os/user"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/paketo-buildpacks/libpak/sbom"
 
@@ -69,36 +70,132 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	}
 	dc.Logger = b.Logger
 
+	project, err := ProjectPath(cr, context.Application.Path)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve project path\n%w", err)
+	}
+	if project != "." {
+		b.Logger.Bodyf("Building project %s", project)
+	}
+
+	pomFile, pomFileSet := cr.Resolve("BP_MAVEN_POM_FILE")
+	if pomFileSet {
+		if filepath.IsAbs(pomFile) {
+			if pomFile, err = filepath.Rel(context.Application.Path, pomFile); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to resolve POM relative to application\n%w", err)
+			}
+		}
+	} else {
+		pomFile = filepath.Join(project, pomFile)
+
+		if _, err := os.Stat(filepath.Join(context.Application.Path, pomFile)); os.IsNotExist(err) {
+			if polyglot, ok, err := PolyglotPOM(filepath.Join(context.Application.Path, project)); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to find polyglot POM\n%w", err)
+			} else if ok {
+				// Maven locates the polyglot POM itself, the buildpack falls back to defaults instead of reading it
+				b.Logger.Bodyf("Using Polyglot Maven POM %s", polyglot)
+			}
+		}
+	}
+
+	// the POM only refines the defaults of the build, Maven reports the errors of a POM it cannot read itself
+	pom, pomErr := ReadPOM(filepath.Join(context.Application.Path, pomFile))
+	if pomErr != nil {
+		b.Logger.Bodyf("WARNING: unable to read POM, using defaults\n%s", pomErr)
+	}
+
+	version := MavenVersion(cr, filepath.Join(context.Application.Path, project), pom)
+	_, versionSet := cr.Resolve("BP_MAVEN_VERSION")
+	verify := cr.ResolveBool("BP_MAVEN_VERIFY_SIGNATURES")
+
+	// the version of Maven that runs the build, if known.  Options of newer versions are only used when it is known to
+	// support them.
+	mavenVersion := ""
 	command := ""
+	var mvnd *MvndExecutor
 	if cr.ResolveBool("BP_MAVEN_DAEMON_ENABLED") {
 		dep, err := dr.Resolve("mvnd", "")
 		if err != nil {
 			return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
 		}
 
+		mavenVersion, _ = MvndMavenVersion(dep.Version)
+
 		dist, be := NewMvndDistribution(dep, dc)
 		dist.Logger = b.Logger
+		if verify {
+			v, err := NewSignatureVerifier(dep, dr, dc, filepath.Join(context.Buildpack.Path, "KEYS"))
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to create signature verifier\n%w", err)
+			}
+			dist.Verifier = &v
+		}
 		result.Layers = append(result.Layers, dist)
 		result.BOM.Entries = append(result.BOM.Entries, be)
 
-		command = filepath.Join(context.Layers.Path, dist.Name(), "bin", "mvnd")
+		daemon := MvndDaemon{Logger: b.Logger}
+		result.Layers = append(result.Layers, daemon)
+
+		client := "mvnd"
+		switch c, _ := cr.Resolve("BP_MAVEN_DAEMON_CLIENT"); c {
+		case "", MvndClientNative:
+		case MvndClientJVM:
+			client = "mvnd.sh"
+		default:
+			return libcnb.BuildResult{}, fmt.Errorf("unknown Maven Daemon client %s, must be one of %s or %s",
+				c, MvndClientNative, MvndClientJVM)
+		}
+
+		opts, err := libbs.ResolveArguments("BP_MAVEN_DAEMON_OPTS", cr)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve daemon options\n%w", err)
+		}
+
+		command = filepath.Join(context.Layers.Path, dist.Name(), "bin", client)
+		mvnd = &MvndExecutor{
+			Arguments: append(MvndArguments(filepath.Join(context.Layers.Path, daemon.Name()), "/sys/fs/cgroup"), opts...),
+			Home:      filepath.Join(context.Layers.Path, dist.Name()),
+			Logger:    b.Logger,
+			Stopper:   effect.NewExecutor(),
+		}
 	} else {
-		command = filepath.Join(context.Application.Path, "mvnw")
-		if _, err := os.Stat(command); os.IsNotExist(err) {
-			dep, err := dr.Resolve("maven", "")
+		// the Maven Wrapper beside the POM, or in a parent directory locating .mvn itself
+		wrapper, ok, err := Wrapper(context.Application.Path, project)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to find Maven Wrapper\n%w", err)
+		}
+
+		if !ok {
+			dep, err := dr.Resolve("maven", MavenVersionConstraint(version))
+			if err != nil && !versionSet && IsMaven4(version) {
+				b.Logger.Body("WARNING: the application requires Maven 4, which is not provided by the buildpack, " +
+					"building with Maven 3. Set $BP_MAVEN_VERSION or provide a Maven Wrapper to select the version of Maven")
+				version = "3"
+				dep, err = dr.Resolve("maven", MavenVersionConstraint(version))
+			}
 			if err != nil {
-				return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
+				return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency for Maven %s, "+
+					"set $BP_MAVEN_VERSION or provide a Maven Wrapper\n%w", version, err)
 			}
+			mavenVersion = dep.Version
 
 			dist, be := NewDistribution(dep, dc)
 			dist.Logger = b.Logger
+			if verify {
+				v, err := NewSignatureVerifier(dep, dr, dc, filepath.Join(context.Buildpack.Path, "KEYS"))
+				if err != nil {
+					return libcnb.BuildResult{}, fmt.Errorf("unable to create signature verifier\n%w", err)
+				}
+				dist.Verifier = &v
+			}
 			result.Layers = append(result.Layers, dist)
 			result.BOM.Entries = append(result.BOM.Entries, be)
 
 			command = filepath.Join(context.Layers.Path, dist.Name(), "bin", "mvn")
-		} else if err != nil {
-			return libcnb.BuildResult{}, fmt.Errorf("unable to stat %s\n%w", command, err)
 		} else {
+			mavenVersion, _ = WrapperMavenVersion(wrapper)
+
+			command = wrapper
 			if err := os.Chmod(command, 0755); err != nil {
 				b.Logger.Bodyf("WARNING: unable to chmod %s:\n%s", command, err)
 			}
@@ -109,31 +206,80 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		}
 	}
 
-	u, err := user.Current()
-	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to determine user home directory\n%w", err)
-	}
-
-	c := libbs.Cache{Path: filepath.Join(u.HomeDir, ".m2")}
+	c := libbs.Cache{Path: MavenUserHome(context.Layers.Path)}
 	c.Logger = b.Logger
 	result.Layers = append(result.Layers, c)
 
+	repository := RepositoryPath(cr, c.Path)
+
 	args, err := libbs.ResolveArguments("BP_MAVEN_BUILD_ARGUMENTS", cr)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve build arguments\n%w", err)
 	}
 
-	pomFile, userSet := cr.Resolve("BP_MAVEN_POM_FILE")
-	if userSet {
-		args = append([]string{"--file", pomFile}, args...)
+	if pomFileSet {
+		file, err := filepath.Rel(project, pomFile)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve %s relative to %s\n%w", pomFile, project, err)
+		}
+		args = append([]string{"--file", file}, args...)
+	}
+
+	if timestamp, ok, err := OutputTimestamp(cr, context.Application.Path, effect.NewExecutor()); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve output timestamp\n%w", err)
+	} else if ok {
+		if pomErr != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to read POM of reproducible build\n%w", pomErr)
+		}
+
+		if _, ok := pom.Properties[OutputTimestampProperty]; ok {
+			b.Logger.Bodyf("POM defines %s, not overriding it", OutputTimestampProperty)
+		} else {
+			b.Logger.Bodyf("Setting %s to %s", OutputTimestampProperty, timestamp)
+			args = append([]string{fmt.Sprintf("-D%s=%s", OutputTimestampProperty, timestamp)}, args...)
+		}
+
+		if warnings := ReproducibilityWarnings(pom); len(warnings) > 0 {
+			for _, w := range warnings {
+				b.Logger.Bodyf("WARNING: %s", w)
+			}
+		} else {
+			b.Logger.Body("Application artifact is reproducible-ready")
+		}
 	}
 
-	if !b.TTY && !contains(args, []string{"-B", "--batch-mode"}) {
+	if !b.TTY && !contains(args, []string{"-B", "--batch-mode", "--non-interactive"}) {
 		// terminal is not tty, and the user did not set batch mode; let's set it
-		args = append([]string{"--batch-mode"}, args...)
+		if IsMaven4(mavenVersion) {
+			// --batch-mode is deprecated by Maven 4
+			args = append([]string{"--non-interactive"}, args...)
+		} else {
+			args = append([]string{"--batch-mode"}, args...)
+		}
+	}
+
+	var node Node
+	yarn := false
+	// the Node.js buildpacks provide node and yarn on $PATH, without an entry in the plan of this buildpack
+	if _, err := exec.LookPath("node"); err == nil {
+		if node, err = NewNode(context.Application.Path, pomFile); err != nil {
+			b.Logger.Bodyf("WARNING: unable to inspect Node.js usage\n%s", err)
+		}
+
+		_, err := exec.LookPath("yarn")
+		yarn = err == nil && node.Yarn
+
+		if len(node.InstallDirectories) > 0 {
+			// the frontend-maven-plugin runs the tools on $PATH instead of downloading them
+			if yarn {
+				args = append([]string{"-Dskip.installyarn"}, args...)
+			}
+			args = append([]string{"-Dskip.installnodenpm"}, args...)
+		}
 	}
 
 	md := map[string]interface{}{}
+	settings := ""
 	if binding, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType("maven")); err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve binding\n%w", err)
 	} else if ok {
@@ -141,13 +287,126 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		if err != nil {
 			return libcnb.BuildResult{}, fmt.Errorf("unable to process maven settings from binding\n%w", err)
 		}
+		settings, _ = binding.SecretFilePath("settings.xml")
+	}
+
+	if proxies, err := Proxies(); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve proxies\n%w", err)
+	} else if len(proxies) > 0 && !globalSettings(args) {
+		// the proxies of the bound settings take precedence over the environment
+		configured := false
+		if settings != "" {
+			if configured, err = SettingsProxies(settings); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read proxies of maven settings from binding\n%w", err)
+			}
+		}
+
+		if configured {
+			b.Logger.Body("Using the proxies of the bound settings.xml")
+		} else {
+			ps := ProxySettings{Logger: b.Logger, Proxies: proxies}
+			result.Layers = append(result.Layers, ps)
+
+			args = append([]string{fmt.Sprintf("--global-settings=%s",
+				filepath.Join(context.Layers.Path, ps.Name(), ProxySettingsFile))}, args...)
+		}
+	}
+
+	trustStore := ""
+	if certificates := BoundCertificates(context.Platform.Bindings); len(certificates) > 0 {
+		ca := NewCACertificates(certificates)
+		ca.Logger = b.Logger
+		result.Layers = append(result.Layers, ca)
+
+		trustStore = filepath.Join(context.Layers.Path, ca.Name(), TrustStoreFile)
+	}
+
+	selection, err := ArtifactSelector{
+		ApplicationPath:       context.Application.Path,
+		Arguments:             args,
+		ConfigurationResolver: cr,
+		Logger:                b.Logger,
+		POM:                   pom,
+		POMFile:               pomFile,
+		Project:               project,
+	}.Select()
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to select application artifact\n%w", err)
+	}
+	args = append(args, selection.Arguments...)
+	result.Processes = append(result.Processes, selection.Processes...)
+
+	if selection.ModuleSet && selection.Module != project && !containsOption(args, "-pl", "--projects") {
+		// only build the configured modules and the modules they depend on, relative to the project.  Located modules
+		// are built with the whole reactor, as they may use the output of modules they do not depend on.
+		var projects []string
+		for _, m := range ParseModules(selection.Module) {
+			r, err := filepath.Rel(project, m)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to resolve %s relative to %s\n%w", m, project, err)
+			}
+			projects = append(projects, r)
+		}
+
+		args = append([]string{"--projects", strings.Join(projects, ","), "--also-make"}, args...)
+	}
+
+	if selection.Modules != nil {
+		var descriptions []map[string]interface{}
+		for _, m := range selection.Modules.Modules {
+			descriptions = append(descriptions, map[string]interface{}{
+				"name":             m.Name,
+				"module":           m.Path,
+				"artifact-pattern": m.Pattern,
+				"artifact":         m.Artifact,
+			})
+		}
+		md["modules"] = descriptions
+	}
+
+	// Maven retries requests to repositories itself, before the build is retried
+	args = append(RepositoryArguments(args), args...)
+
+	if bases, err := BaseRepositories(cr, context.Platform.Bindings); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve base repositories\n%w", err)
+	} else if _, ok := property(args, "maven.repo.local.tail"); !ok && len(bases) > 0 {
+		// Maven 3.9 resolves artifacts from the read-only tail before downloading them into the local repository
+		b.Logger.Bodyf("Using base repositories %s", strings.Join(bases, ", "))
+		if IsMavenBefore(mavenVersion, "3.9.0") && mvnd != nil {
+			b.Logger.Bodyf("WARNING: Maven %s of the Maven Daemon ignores the base repositories, which require Maven 3.9. "+
+				"Disable the Maven Daemon, or provide a version of it embedding Maven 3.9 or later", mavenVersion)
+		} else if IsMavenBefore(mavenVersion, "3.9.0") {
+			b.Logger.Bodyf("WARNING: Maven %s ignores the base repositories, which require Maven 3.9. "+
+				"Set $BP_MAVEN_VERSION or provide a Maven Wrapper to build with Maven 3.9 or later", mavenVersion)
+		}
+		args = append([]string{fmt.Sprintf("-Dmaven.repo.local.tail=%s", strings.Join(bases, ","))}, args...)
+	}
+
+	// the local repository of the bound settings takes precedence over the default one
+	local, localSet := "", false
+	if _, ok := cr.Resolve("BP_MAVEN_REPOSITORY_PATH"); !ok && settings != "" {
+		// settings that cannot be read are reported by Maven
+		if local, localSet, err = SettingsLocalRepository(settings); err != nil {
+			b.Logger.Bodyf("WARNING: unable to read local repository of maven settings from binding\n%s", err)
+		}
+	}
+
+	if s, ok := property(args, "maven.repo.local"); ok && s != "" {
+		repository = s
+	} else if !ok && localSet {
+		b.Logger.Bodyf("Using the local repository %s of the bound settings.xml", local)
+		repository = local
+	} else if !ok {
+		// the local repository is explicit, rather than derived by Maven from the home directory of the build user
+		args = append([]string{fmt.Sprintf("-Dmaven.repo.local=%s", repository)}, args...)
 	}
 
 	art := libbs.ArtifactResolver{
 		ArtifactConfigurationKey: "BP_MAVEN_BUILT_ARTIFACT",
-		ConfigurationResolver:    cr,
-		ModuleConfigurationKey:   "BP_MAVEN_BUILT_MODULE",
-		InterestingFileDetector:  libbs.JARInterestingFileDetector{},
+		ConfigurationResolver:    selection.Resolver,
+		ModuleConfigurationKey:   selection.ModuleConfigurationKey,
+		InterestingFileDetector:  selection.InterestingFileDetector,
+		AdditionalHelpMessage:    "Set $BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER or $BP_MAVEN_BUILT_ARTIFACT to select one",
 	}
 
 	bomScanner := sbom.NewSyftCLISBOMScanner(context.Layers, effect.NewExecutor(), b.Logger)
@@ -167,8 +426,95 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	}
 
 	a.Logger = b.Logger
+
+	if cr.ResolveBool("BP_MAVEN_TIMING") {
+		timing := Timing{}
+		result.Layers = append(result.Layers, timing)
+
+		a.Executor = TimingExecutor{
+			Delegate: a.Executor,
+			Logger:   b.Logger,
+			Report:   filepath.Join(context.Layers.Path, timing.Name(), TimingReportFile),
+		}
+	}
+
+	if retries, err := Retries(cr); err != nil {
+		return libcnb.BuildResult{}, err
+	} else if retries > 0 {
+		a.Executor = RetryExecutor{
+			Backoff:  RetryBackoff,
+			Delegate: a.Executor,
+			Logger:   b.Logger,
+			Retries:  retries,
+		}
+	}
+
+	if trustStore != "" && mvnd != nil {
+		// the daemon, not the client, resolves artifacts and does not read $MAVEN_OPTS
+		mvnd.Arguments = MvndJVMArguments(mvnd.Arguments, TrustStoreOptions(trustStore))
+	} else if trustStore != "" {
+		a.Executor = TrustStoreExecutor{Delegate: a.Executor, TrustStore: trustStore}
+	}
+
+	if mode, _ := cr.Resolve("BP_MAVEN_LOCKFILE_MODE"); mode != "" && mode != LockfileModeDisabled {
+		lockfile, _ := cr.Resolve("BP_MAVEN_LOCKFILE")
+		l, err := NewLockfile(context.Application.Path, lockfile, mode, repository)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to create lock file\n%w", err)
+		}
+		l.Logger = b.Logger
+
+		if tail, _ := property(args, "maven.repo.local.tail"); tail != "" {
+			b.Logger.Bodyf("WARNING: artifacts resolved from the base repositories %s are not checked against the lock file",
+				tail)
+		}
+
+		// the lock file is checked once Maven has run, against the access times it reset, so that a reused application
+		// layer is not checked against artifacts it did not resolve
+		a.Executor = LockfileExecutor{Delegate: a.Executor, Lockfile: l}
+	}
+
+	if project != "." {
+		a.Executor = WorkingDirectoryExecutor{
+			Delegate:  a.Executor,
+			Directory: filepath.Join(context.Application.Path, project),
+		}
+	}
+
+	if len(node.InstallDirectories) > 0 {
+		a.Executor = NodeExecutor{
+			ApplicationPath:    context.Application.Path,
+			Delegate:           a.Executor,
+			InstallDirectories: node.InstallDirectories,
+			Logger:             b.Logger,
+			Yarn:               yarn,
+		}
+	}
+
+	if mvnd != nil {
+		mvnd.Delegate = a.Executor
+		a.Executor = *mvnd
+	}
+
+	a.Executor = selection.Executor(a.Executor)
+
 	result.Layers = append(result.Layers, a)
 
+	if selection.Native == nil && selection.Modules == nil {
+		if framework, _, err := ReactorFramework(context.Application.Path, pomFile); err != nil {
+			b.Logger.Bodyf("WARNING: unable to detect framework\n%s", err)
+		} else if framework == FrameworkSpringBoot {
+			// the layers of a layered jar are only known once it has been built and restored
+			for _, name := range SpringBootLayerNames {
+				result.Layers = append(result.Layers, SpringBootLayer{
+					ApplicationPath: context.Application.Path,
+					Index:           name,
+					Logger:          b.Logger,
+				})
+			}
+		}
+	}
+
 	return result, nil
 }
 
@@ -208,6 +554,22 @@ func handleMavenSettings(binding libcnb.Binding, args []string, md map[string]in
 	return args, nil
 }
 
+// withDefault returns a copy of a configuration resolver with the default value of the named configuration replaced.
+func withDefault(cr libpak.ConfigurationResolver, name string, value string) libpak.ConfigurationResolver {
+	configurations := make([]libpak.BuildpackConfiguration, len(cr.Configurations))
+	copy(configurations, cr.Configurations)
+
+	for i := range configurations {
+		if configurations[i].Name == name {
+			configurations[i].Default = value
+			return libpak.ConfigurationResolver{Configurations: configurations}
+		}
+	}
+
+	configurations = append(configurations, libpak.BuildpackConfiguration{Name: name, Default: value})
+	return libpak.ConfigurationResolver{Configurations: configurations}
+}
+
 func contains(strings []string, stringsSearchedAfter []string) bool {
 	for _, v := range strings {
 		for _, stringSearchedAfter := range stringsSearchedAfter {
@@ -219,6 +581,19 @@ func contains(strings []string, stringsSearchedAfter []string) bool {
 	return false
 }
 
+// containsOption returns whether args contain one of options, either as a separate argument, joined to its value by
+// '=', or, for a short option, directly followed by its value.
+func containsOption(args []string, options ...string) bool {
+	for _, a := range args {
+		for _, o := range options {
+			if a == o || strings.HasPrefix(a, o+"=") || (!strings.HasPrefix(o, "--") && strings.HasPrefix(a, o)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (b Build) CleanMvnWrapper(fileName string) error {
 
 	fileContents, err := ioutil.ReadFile(fileName)
@@ -31,6 +31,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/libbs"
 	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
 	"github.com/sclevine/spec"
 
 	"github.com/paketo-buildpacks/maven/v6/maven"
@@ -40,11 +42,24 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
-		ctx          libcnb.BuildContext
-		mavenBuild   maven.Build
-		mvnwFilepath string
+		ctx                libcnb.BuildContext
+		mavenBuild         maven.Build
+		mvnwFilepath       string
+		repositoryArgument string
 	)
 
+	repositoryProperties := []string{
+		"-Daether.connector.http.retryHandler.count=3",
+		"-Daether.connector.connectTimeout=30000",
+		"-Daether.connector.requestTimeout=300000",
+		"-Dmaven.wagon.http.retryHandler.count=3",
+	}
+
+	// arguments returns the default arguments, the local repository and repository properties, followed by args
+	arguments := func(args ...string) []string {
+		return append(append([]string{repositoryArgument}, repositoryProperties...), args...)
+	}
+
 	it.Before(func() {
 		var err error
 
@@ -54,6 +69,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		ctx.Buildpack.Metadata = map[string]interface{}{
 			"configurations": []map[string]interface{}{
 				{"name": "BP_MAVEN_BUILD_ARGUMENTS", "default": "test-argument"},
+				{"name": "BP_MAVEN_BUILT_ARTIFACT", "default": "target/*.[ejw]ar"},
+				{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
 			},
 		}
 
@@ -65,6 +82,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		}
 
 		mvnwFilepath = filepath.Join(ctx.Application.Path, "mvnw")
+		repositoryArgument = fmt.Sprintf("-Dmaven.repo.local=%s",
+			filepath.Join(maven.MavenUserHome(ctx.Layers.Path), "repository"))
 	})
 
 	it.After(func() {
@@ -80,11 +99,184 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		result, err := mavenBuild.Build(ctx)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal([]string{
+		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
 			"--batch-mode",
 			"test-argument",
-		}))
+		)))
+
+	})
+
+	context("the Maven Wrapper downloads Maven", func() {
+		// wrapper writes the Maven Wrapper, downloading Maven version
+		wrapper := func(version string) {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, ".mvn", "wrapper"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, ".mvn", "wrapper", "maven-wrapper.properties"),
+				[]byte(fmt.Sprintf("distributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/"+
+					"%[1]s/apache-maven-%[1]s-bin.zip\n", version)), 0644)).To(Succeed())
+		}
+
+		it.Before(func() {
+			ctx.StackID = "test-stack-id"
+			mavenBuild.TTY = false
+		})
+
+		it("adds --non-interactive for Maven 4 if terminal is not tty", func() {
+			wrapper("4.0.0")
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
+				"--non-interactive",
+				"test-argument",
+			)))
+		})
+
+		it("adds --batch-mode for Maven 3 in a project requiring Maven 4", func() {
+			wrapper("3.8.6")
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, ".mvn", "maven-user.properties"), []byte{}, 0644)).
+				To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
+				"--batch-mode",
+				"test-argument",
+			)))
+		})
+
+		it("warns that Maven before 3.9 ignores the base repositories", func() {
+			wrapper("3.8.6")
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+			}
+			defer func() { ctx.Platform.Bindings = nil }()
+			buf := &bytes.Buffer{}
+			mavenBuild.Logger = bard.NewLogger(buf)
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring("WARNING: Maven 3.8.6 ignores the base repositories"))
+		})
+
+		it("does not warn about the base repositories with Maven 3.9", func() {
+			wrapper("3.9.6")
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+			}
+			defer func() { ctx.Platform.Bindings = nil }()
+			buf := &bytes.Buffer{}
+			mavenBuild.Logger = bard.NewLogger(buf)
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring("Using base repositories /bindings/shared"))
+			Expect(buf.String()).NotTo(ContainSubstring("ignores the base repositories"))
+		})
+	})
+
+	context("the POM requires Maven 4", func() {
+		it.Before(func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"),
+				[]byte(`<project><modelVersion>4.1.0</modelVersion></project>`), 0644)).To(Succeed())
+			ctx.StackID = "test-stack-id"
+		})
+
+		it("contributes a Maven 4 distribution", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+				{
+					"id":      "maven",
+					"version": "4.0.0",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+
+			mavenBuild.TTY = false
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(maven.Distribution).LayerContributor.Dependency.Version).To(Equal("4.0.0"))
+			Expect(result.Layers[2].(libbs.Application).Arguments).To(ContainElement("--non-interactive"))
+		})
+
+		it("builds with Maven 3 without a Maven 4 distribution", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+			mavenBuild.TTY = false
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(maven.Distribution).LayerContributor.Dependency.Version).To(Equal("3.8.6"))
+			Expect(result.Layers[2].(libbs.Application).Arguments).To(ContainElement("--batch-mode"))
+		})
+
+		it("fails without the Maven 4 distribution of $BP_MAVEN_VERSION", func() {
+			Expect(os.Setenv("BP_MAVEN_VERSION", "4")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_VERSION")
+
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to find dependency for Maven 4")))
+		})
+
+		it("uses $BP_MAVEN_VERSION", func() {
+			Expect(os.Setenv("BP_MAVEN_VERSION", "3")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_VERSION")
+
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(maven.Distribution).LayerContributor.Dependency.Version).To(Equal("3.8.6"))
+		})
+	})
 
+	it("builds a polyglot POM without --file", func() {
+		Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, ".mvn"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, ".mvn", "extensions.xml"), []byte(`<extensions>
+  <extension>
+    <groupId>io.takari.polyglot</groupId>
+    <artifactId>polyglot-kotlin</artifactId>
+  </extension>
+</extensions>`), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.kts"), []byte{}, 0644)).To(Succeed())
+		ctx.StackID = "test-stack-id"
+
+		result, err := mavenBuild.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
 	})
 
 	context("BP_MAVEN_POM_FILE is set", func() {
@@ -96,13 +288,172 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(os.Unsetenv(("BP_MAVEN_POM_FILE"))).To(Succeed())
 		})
 
-		it("adds the --file argument if set", func() {
+		it("adds the --file argument relative to the directory of the POM", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("--file", "pom.xml", "test-argument")))
+			Expect(app.Command).To(Equal(mvnwFilepath))
+			Expect(app.Executor).To(Equal(maven.WorkingDirectoryExecutor{
+				Directory: filepath.Join(ctx.Application.Path, "foo", "bar"),
+			}))
+		})
+
+		it("uses the Maven Wrapper beside the POM", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "foo", "bar"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "foo", "bar", "mvnw"), []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Application.Path, "foo", "bar", "mvnw")))
+		})
+
+		it("resolves modules relative to the application root", func() {
+			Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "foo/bar/app")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_BUILT_MODULE")
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("foo/bar/app/target/*.[ejw]ar"))
+			Expect(app.Arguments).To(Equal(arguments("--projects", "app", "--also-make", "--file", "pom.xml", "test-argument")))
+		})
+
+		it("accepts an absolute path", func() {
+			Expect(os.Setenv("BP_MAVEN_POM_FILE", filepath.Join(ctx.Application.Path, "foo", "bar", "pom.xml"))).To(Succeed())
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("--file", "pom.xml", "test-argument")))
+			Expect(app.Executor).To(Equal(maven.WorkingDirectoryExecutor{
+				Directory: filepath.Join(ctx.Application.Path, "foo", "bar"),
+			}))
+		})
+	})
+
+	context("SOURCE_DATE_EPOCH is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("SOURCE_DATE_EPOCH", "1640995200")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+		})
+
+		it("adds the output timestamp argument", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
+				"-Dproject.build.outputTimestamp=2022-01-01T00:00:00Z",
+				"test-argument",
+			)))
+		})
+
+		it("does not add the output timestamp argument if the POM defines it", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <properties>
+    <project.build.outputTimestamp>2020-01-01T00:00:00Z</project.build.outputTimestamp>
+  </properties>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
+		})
+
+		it("fails if the POM cannot be read", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte("<project>"), 0644)).To(Succeed())
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to read POM of reproducible build")))
+		})
+	})
+
+	it("builds with defaults if the POM cannot be read", func() {
+		Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte("<project>"), 0644)).To(Succeed())
+
+		result, err := mavenBuild.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
+	})
+
+	context("BP_MAVEN_TIMING is true", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_TIMING", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_TIMING")).To(Succeed())
+		})
+
+		it("reports the timing of the build", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[1]).To(Equal(maven.Timing{}))
+			Expect(result.Layers[2].(libbs.Application).Executor).To(Equal(maven.TimingExecutor{
+				Logger: mavenBuild.Logger,
+				Report: filepath.Join(ctx.Layers.Path, "timing", "timing.json"),
+			}))
+		})
+	})
+
+	context("BP_MAVEN_RETRIES", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_RETRIES")).To(Succeed())
+		})
+
+		it("retries builds failing with transient repository failures", func() {
+			Expect(os.Setenv("BP_MAVEN_RETRIES", "3")).To(Succeed())
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Executor).To(Equal(maven.RetryExecutor{
+				Backoff: maven.RetryBackoff,
+				Logger:  mavenBuild.Logger,
+				Retries: 3,
+			}))
+		})
+
+		it("does not retry builds if zero", func() {
+			Expect(os.Setenv("BP_MAVEN_RETRIES", "0")).To(Succeed())
 			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
 
 			result, err := mavenBuild.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers[1].(libbs.Application).Arguments[0:2]).To(Equal([]string{"--file", "foo/bar/pom.xml"}))
+			Expect(result.Layers[1].(libbs.Application).Executor).To(BeNil())
+		})
+
+		it("fails if invalid", func() {
+			Expect(os.Setenv("BP_MAVEN_RETRIES", "-1")).To(Succeed())
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError("invalid $BP_MAVEN_RETRIES -1, must be a non-negative number"))
 		})
 	})
 
@@ -123,10 +474,10 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := mavenBuild.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal([]string{
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
 				"--batch-mode",
 				"user-provided-argument",
-			}))
+			)))
 		})
 	})
 
@@ -144,7 +495,46 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.Layers[0].Name()).To(Equal("cache"))
 		Expect(result.Layers[1].Name()).To(Equal("application"))
 		Expect(result.Layers[1].(libbs.Application).Command).To(Equal(mvnwFilepath))
-		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal([]string{"test-argument"}))
+		Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
+	})
+
+	it("uses $BP_MAVEN_REPOSITORY_PATH as the local repository", func() {
+		Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		Expect(os.Setenv("BP_MAVEN_REPOSITORY_PATH", "/test/repository")).To(Succeed())
+		defer os.Unsetenv("BP_MAVEN_REPOSITORY_PATH")
+
+		result, err := mavenBuild.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libbs.Application).Arguments).
+			To(Equal(append([]string{"-Dmaven.repo.local=/test/repository"}, append(repositoryProperties, "test-argument")...)))
+	})
+
+	it("does not override the local repository in the build arguments", func() {
+		Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "-Dmaven.repo.local=/test/repository package")).To(Succeed())
+		defer os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")
+
+		result, err := mavenBuild.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libbs.Application).Arguments).
+			To(Equal(append(repositoryProperties, "-Dmaven.repo.local=/test/repository", "package")))
+	})
+
+	it("chains the base repositories behind the local repository", func() {
+		Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		ctx.Platform.Bindings = libcnb.Bindings{
+			{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+		}
+		defer func() { ctx.Platform.Bindings = nil }()
+
+		result, err := mavenBuild.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libbs.Application).Arguments).
+			To(Equal(append([]string{repositoryArgument, "-Dmaven.repo.local.tail=/bindings/shared"},
+				append(repositoryProperties, "test-argument")...)))
 	})
 
 	it("makes sure that mvnw is executable", func() {
@@ -182,7 +572,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
 			{
 				"id":      "maven",
-				"version": "1.1.1",
+				"version": "3.8.6",
 				"stacks":  []interface{}{"test-stack-id"},
 				"cpes":    []string{"cpe:2.3:a:apache:maven:3.8.3:*:*:*:*:*:*:*"},
 				"purl":    "pkg:generic/apache-maven@3.8.3",
@@ -198,7 +588,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.Layers[1].Name()).To(Equal("cache"))
 		Expect(result.Layers[2].Name()).To(Equal("application"))
 		Expect(result.Layers[2].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "maven", "bin", "mvn")))
-		Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal([]string{"test-argument"}))
+		Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
 
 		Expect(result.BOM.Entries).To(HaveLen(1))
 		Expect(result.BOM.Entries[0].Name).To(Equal("maven"))
@@ -206,11 +596,53 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.BOM.Entries[0].Launch).To(BeFalse())
 	})
 
+	context("BP_MAVEN_VERIFY_SIGNATURES is true", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_VERIFY_SIGNATURES", "true")).To(Succeed())
+			ctx.StackID = "test-stack-id"
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_VERIFY_SIGNATURES")).To(Succeed())
+		})
+
+		it("fails without a signature", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to find signature of maven 3.8.6")))
+		})
+
+		it("fails without keys", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+				{
+					"id":      "maven-signature",
+					"version": "3.8.6",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("unable to open %s", filepath.Join(ctx.Buildpack.Path, "KEYS")))))
+		})
+	})
+
 	it("contributes distribution for API <=0.6", func() {
 		ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
 			{
 				"id":      "maven",
-				"version": "1.1.1",
+				"version": "3.8.6",
 				"stacks":  []interface{}{"test-stack-id"},
 			},
 		}
@@ -225,7 +657,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.Layers[1].Name()).To(Equal("cache"))
 		Expect(result.Layers[2].Name()).To(Equal("application"))
 		Expect(result.Layers[2].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "maven", "bin", "mvn")))
-		Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal([]string{"test-argument"}))
+		Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
 
 		Expect(result.BOM.Entries).To(HaveLen(1))
 		Expect(result.BOM.Entries[0].Name).To(Equal("maven"))
@@ -257,12 +689,13 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := mavenBuild.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(4))
 			Expect(result.Layers[0].Name()).To(Equal("mvnd"))
-			Expect(result.Layers[1].Name()).To(Equal("cache"))
-			Expect(result.Layers[2].Name()).To(Equal("application"))
-			Expect(result.Layers[2].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd", "bin", "mvnd")))
-			Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal([]string{"test-argument"}))
+			Expect(result.Layers[1].Name()).To(Equal("mvnd-daemon"))
+			Expect(result.Layers[2].Name()).To(Equal("cache"))
+			Expect(result.Layers[3].Name()).To(Equal("application"))
+			Expect(result.Layers[3].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd", "bin", "mvnd")))
+			Expect(result.Layers[3].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
 
 			Expect(result.BOM.Entries).To(HaveLen(1))
 			Expect(result.BOM.Entries[0].Name).To(Equal("mvnd"))
@@ -270,7 +703,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(result.BOM.Entries[0].Launch).To(BeFalse())
 		})
 
-		it("contributes mvnd distribution for API <=0.6", func() {
+		it("configures and stops the daemon", func() {
 			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
 				{
 					"id":      "mvnd",
@@ -279,20 +712,165 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				},
 			}
 			ctx.StackID = "test-stack-id"
-			ctx.Buildpack.API = "0.6"
 
 			result, err := mavenBuild.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
-			Expect(result.Layers[0].Name()).To(Equal("mvnd"))
-			Expect(result.Layers[1].Name()).To(Equal("cache"))
-			Expect(result.Layers[2].Name()).To(Equal("application"))
-			Expect(result.Layers[2].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd", "bin", "mvnd")))
-			Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal([]string{"test-argument"}))
+			executor, ok := result.Layers[3].(libbs.Application).Executor.(maven.MvndExecutor)
+			Expect(ok).To(BeTrue())
+			Expect(executor.Home).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd")))
+			Expect(executor.Arguments).To(ContainElements(
+				fmt.Sprintf("-Dmvnd.daemonStorage=%s", filepath.Join(ctx.Layers.Path, "mvnd-daemon")),
+				"-Dmvnd.idleTimeout=5m",
+			))
+		})
 
-			Expect(result.BOM.Entries).To(HaveLen(1))
-			Expect(result.BOM.Entries[0].Name).To(Equal("mvnd"))
+		it("warns that the Maven of an older daemon ignores the base repositories", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "mvnd",
+					"version": "0.8.0",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+			}
+			defer func() { ctx.Platform.Bindings = nil }()
+			buf := &bytes.Buffer{}
+			mavenBuild.Logger = bard.NewLogger(buf)
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring("WARNING: Maven 3.8 of the Maven Daemon ignores the base repositories"))
+		})
+
+		it("times the build but not the stopping of the daemon", func() {
+			Expect(os.Setenv("BP_MAVEN_TIMING", "true")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_TIMING")
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "mvnd",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			executor, ok := result.Layers[4].(libbs.Application).Executor.(maven.MvndExecutor)
+			Expect(ok).To(BeTrue())
+			Expect(executor.Delegate).To(Equal(maven.TimingExecutor{
+				Logger: mavenBuild.Logger,
+				Report: filepath.Join(ctx.Layers.Path, "timing", "timing.json"),
+			}))
+			Expect(executor.Stopper).To(Equal(effect.NewExecutor()))
+		})
+
+		context("BP_MAVEN_DAEMON_CLIENT and BP_MAVEN_DAEMON_OPTS are set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_MAVEN_DAEMON_CLIENT", "jvm")).To(Succeed())
+				Expect(os.Setenv("BP_MAVEN_DAEMON_OPTS", "-Dmvnd.threads=2 -Dmvnd.jvmArgs='-Xss1m -Xshare:off'")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_DAEMON_CLIENT")).To(Succeed())
+				Expect(os.Unsetenv("BP_MAVEN_DAEMON_OPTS")).To(Succeed())
+			})
+
+			it("uses the JVM client and passes the options", func() {
+				ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+					{
+						"id":      "mvnd",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				}
+				ctx.StackID = "test-stack-id"
+
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[3].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd", "bin", "mvnd.sh")))
+				executor, ok := result.Layers[3].(libbs.Application).Executor.(maven.MvndExecutor)
+				Expect(ok).To(BeTrue())
+				Expect(executor.Arguments[len(executor.Arguments)-2:]).To(Equal([]string{"-Dmvnd.threads=2", "-Dmvnd.jvmArgs=-Xss1m -Xshare:off"}))
+			})
+
+			it("passes the truststore to the daemon's JVM", func() {
+				ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+					{
+						"id":      "mvnd",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				}
+				ctx.StackID = "test-stack-id"
+				ctx.Platform.Bindings = libcnb.Bindings{
+					{
+						Name:   "corporate",
+						Type:   "ca-certificates",
+						Secret: map[string]string{"root.pem": ""},
+						Path:   "/bindings/corporate",
+					},
+				}
+				defer func() { ctx.Platform.Bindings = nil }()
+
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				executor, ok := result.Layers[4].(libbs.Application).Executor.(maven.MvndExecutor)
+				Expect(ok).To(BeTrue())
+				Expect(executor.Arguments[len(executor.Arguments)-1]).To(Equal(fmt.Sprintf("-Dmvnd.jvmArgs=-Xss1m -Xshare:off "+
+					"-Djavax.net.ssl.trustStore=%s -Djavax.net.ssl.trustStorePassword=changeit -Djavax.net.ssl.trustStoreType=JKS",
+					filepath.Join(ctx.Layers.Path, "ca-certificates", "truststore.jks"))))
+				Expect(executor.Delegate).To(BeNil())
+			})
+
+			it("fails with an unknown client", func() {
+				Expect(os.Setenv("BP_MAVEN_DAEMON_CLIENT", "test-client")).To(Succeed())
+				ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+					{
+						"id":      "mvnd",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				}
+				ctx.StackID = "test-stack-id"
+
+				_, err := mavenBuild.Build(ctx)
+				Expect(err).To(MatchError("unknown Maven Daemon client test-client, must be one of native or jvm"))
+			})
+		})
+
+		it("contributes mvnd distribution for API <=0.6", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{
+					"id":      "mvnd",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+			ctx.Buildpack.API = "0.6"
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			Expect(result.Layers[0].Name()).To(Equal("mvnd"))
+			Expect(result.Layers[1].Name()).To(Equal("mvnd-daemon"))
+			Expect(result.Layers[2].Name()).To(Equal("cache"))
+			Expect(result.Layers[3].Name()).To(Equal("application"))
+			Expect(result.Layers[3].(libbs.Application).Command).To(Equal(filepath.Join(ctx.Layers.Path, "mvnd", "bin", "mvnd")))
+			Expect(result.Layers[3].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
+
+			Expect(result.BOM.Entries).To(HaveLen(1))
+			Expect(result.BOM.Entries[0].Name).To(Equal("mvnd"))
 			Expect(result.BOM.Entries[0].Build).To(BeTrue())
 			Expect(result.BOM.Entries[0].Launch).To(BeFalse())
 		})
@@ -333,10 +911,10 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("provides --settings argument to maven", func() {
-			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal([]string{
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
 				fmt.Sprintf("--settings=%s", filepath.Join(ctx.Platform.Path, "bindings", "some-maven", "settings.xml")),
 				"test-argument",
-			}))
+			)))
 		})
 
 		it("adds the hash of settings.xml to the layer metadata", func() {
@@ -349,6 +927,50 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("maven settings binding configures the local repository", func() {
+		it.Before(func() {
+			ctx.Platform.Path, _ = ioutil.TempDir("", "maven-test-platform")
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{
+					Name: "some-maven",
+					Type: "maven",
+					Path: filepath.Join(ctx.Platform.Path, "bindings", "some-maven"),
+				},
+			}
+			Expect(os.MkdirAll(ctx.Platform.Bindings[0].Path, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Platform.Bindings[0].Path, "settings.xml"), []byte(`<settings>
+  <localRepository>/test-repository</localRepository>
+</settings>`), 0644)).To(Succeed())
+			ctx.Platform.Bindings[0].Secret = map[string]string{"settings.xml": ""}
+		})
+
+		it.After(func() {
+			ctx.Platform.Bindings = nil
+			Expect(os.RemoveAll(ctx.Platform.Path)).To(Succeed())
+			Expect(os.Unsetenv("BP_MAVEN_REPOSITORY_PATH")).To(Succeed())
+		})
+
+		it("does not override the local repository of the settings", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(append(repositoryProperties,
+				fmt.Sprintf("--settings=%s", filepath.Join(ctx.Platform.Bindings[0].Path, "settings.xml")),
+				"test-argument",
+			)))
+		})
+
+		it("overrides the local repository of the settings with $BP_MAVEN_REPOSITORY_PATH", func() {
+			Expect(os.Setenv("BP_MAVEN_REPOSITORY_PATH", "/repository")).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments[0]).To(Equal("-Dmaven.repo.local=/repository"))
+		})
+	})
+
 	context("maven settings incl. settings-security bindings exists", func() {
 		var result libcnb.BuildResult
 
@@ -396,11 +1018,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("provides -Dsettings.security and --settings argument to maven", func() {
-			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal([]string{
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
 				fmt.Sprintf("-Dsettings.security=%s", filepath.Join(ctx.Platform.Path, "bindings", "some-maven", "settings-security.xml")),
 				fmt.Sprintf("--settings=%s", filepath.Join(ctx.Platform.Path, "bindings", "some-maven", "settings.xml")),
 				"test-argument",
-			}))
+			)))
 		})
 
 		it("adds the hash of settings-security.xml and settings.xml to the layer metadata", func() {
@@ -416,6 +1038,639 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("ca-certificates bindings exist", func() {
+		it.Before(func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{
+					Name:   "corporate",
+					Type:   "ca-certificates",
+					Secret: map[string]string{"root.pem": ""},
+					Path:   "/bindings/corporate",
+				},
+			}
+		})
+
+		it.After(func() {
+			ctx.Platform.Bindings = nil
+		})
+
+		it("contributes a truststore used by Maven", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[1].(maven.CACertificates).Certificates).To(Equal([]string{"/bindings/corporate/root.pem"}))
+			Expect(result.Layers[2].(libbs.Application).Executor).To(Equal(maven.TrustStoreExecutor{
+				TrustStore: filepath.Join(ctx.Layers.Path, "ca-certificates", "truststore.jks"),
+			}))
+		})
+	})
+
+	context("HTTPS_PROXY is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")).To(Succeed())
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("HTTPS_PROXY")).To(Succeed())
+			Expect(os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")).To(Succeed())
+		})
+
+		it("contributes proxy settings as global settings", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[1]).To(Equal(maven.ProxySettings{
+				Logger: mavenBuild.Logger,
+				Proxies: []maven.Proxy{
+					{ID: "https-proxy", Protocol: "https", Host: "proxy.example.com", Port: 3128},
+				},
+			}))
+			Expect(result.Layers[2].(libbs.Application).Arguments).To(Equal(arguments(
+				fmt.Sprintf("--global-settings=%s", filepath.Join(ctx.Layers.Path, "proxy-settings", "settings.xml")),
+				"test-argument",
+			)))
+		})
+
+		it("does not contribute proxy settings if the arguments set the global settings", func() {
+			Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "-gs /test/settings.xml package")).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("-gs", "/test/settings.xml", "package")))
+		})
+
+		it("does not contribute proxy settings if the bound settings configure proxies", func() {
+			var err error
+			ctx.Platform.Path, err = ioutil.TempDir("", "maven-test-platform")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(ctx.Platform.Path)
+
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{
+					Name:   "some-maven",
+					Type:   "maven",
+					Secret: map[string]string{"settings.xml": ""},
+					Path:   filepath.Join(ctx.Platform.Path, "bindings", "some-maven"),
+				},
+			}
+			defer func() { ctx.Platform.Bindings = nil }()
+
+			settingsPath, _ := ctx.Platform.Bindings[0].SecretFilePath("settings.xml")
+			Expect(os.MkdirAll(filepath.Dir(settingsPath), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(settingsPath, []byte(`<settings>
+  <proxies>
+    <proxy><id>corporate</id><host>corporate.example.com</host><port>8080</port></proxy>
+  </proxies>
+</settings>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(
+				fmt.Sprintf("--settings=%s", settingsPath),
+				"test-argument",
+			)))
+		})
+	})
+
+	context("POM declares the artifact", func() {
+		it.Before(func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+  <packaging>war</packaging>
+  <build>
+    <finalName>${project.artifactId}</finalName>
+  </build>
+</project>`), 0644)).To(Succeed())
+		})
+
+		it("selects the artifact by packaging and finalName", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("target/*.[ejw]ar"))
+			Expect(app.ArtifactResolver.InterestingFileDetector).To(Equal(maven.ArtifactFileDetector{
+				Delegate: libbs.JARInterestingFileDetector{},
+				Name:     "test-artifact.war",
+			}))
+			Expect(app.Executor).To(BeNil())
+		})
+
+		it("splits the layers of a Spring Boot application", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+  </parent>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[2].Name()).To(Equal("spring-boot-dependencies"))
+			Expect(result.Layers[3].Name()).To(Equal("spring-boot-spring-boot-loader"))
+			Expect(result.Layers[4].Name()).To(Equal("spring-boot-snapshot-dependencies"))
+		})
+
+		it("selects the Quarkus application", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>io.quarkus.platform</groupId>
+        <artifactId>quarkus-maven-plugin</artifactId>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal(
+				"target/quarkus-app/lib target/quarkus-app/*.jar target/quarkus-app/app target/quarkus-app/quarkus"))
+			Expect(app.Executor).To(BeNil())
+		})
+
+		it("selects the artifact repackaged by the spring-boot-maven-plugin", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-maven-plugin</artifactId>
+        <configuration>
+          <classifier>exec</classifier>
+        </configuration>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("target/*.[ejw]ar"))
+			Expect(app.ArtifactResolver.InterestingFileDetector).To(Equal(maven.ArtifactFileDetector{
+				Delegate: libbs.JARInterestingFileDetector{},
+				Name:     "test-artifact-1.0.0-exec.jar",
+			}))
+		})
+
+		context("BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER is set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER", "exec")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_ARTIFACT_CLASSIFIER")).To(Succeed())
+			})
+
+			it("selects the artifact by classifier", func() {
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				app := result.Layers[1].(libbs.Application)
+				Expect(app.ArtifactResolver.Pattern()).To(Equal("target/*.[ejw]ar"))
+				Expect(app.ArtifactResolver.InterestingFileDetector).To(Equal(maven.ArtifactFileDetector{
+					Delegate: libbs.JARInterestingFileDetector{},
+					Name:     "test-artifact-exec.war",
+				}))
+			})
+		})
+
+		context("BP_MAVEN_BUILT_ARTIFACT is set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_ARTIFACT", "target/*.zip")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_ARTIFACT")).To(Succeed())
+			})
+
+			it("does not select the artifact", func() {
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				app := result.Layers[1].(libbs.Application)
+				Expect(app.ArtifactResolver.Pattern()).To(Equal("target/*.zip"))
+				Expect(app.Executor).To(BeNil())
+			})
+		})
+	})
+
+	context("POM declares modules", func() {
+		it.Before(func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <packaging>pom</packaging>
+  <modules>
+    <module>lib</module>
+    <module>app</module>
+  </modules>
+</project>`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "lib", "pom.xml"), []byte(`<project>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+</project>`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "app"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "app", "pom.xml"), []byte(`<project>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <packaging>war</packaging>
+</project>`), 0644)).To(Succeed())
+		})
+
+		it("locates the application module and builds the whole reactor", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("app/target/*.[ejw]ar"))
+			Expect(app.Arguments).To(Equal(arguments("test-argument")))
+		})
+
+		it("fails if several modules are applications", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "lib", "pom.xml"), []byte(`<project>
+  <artifactId>lib</artifactId>
+  <packaging>war</packaging>
+</project>`), 0644)).To(Succeed())
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to locate single application module, candidates: [lib app]")))
+		})
+
+		context("BP_MAVEN_BUILT_MODULE lists several modules", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "lib,app")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_MODULE")).To(Succeed())
+			})
+
+			it("lays out each module under a named subdirectory", func() {
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				app := result.Layers[1].(libbs.Application)
+				Expect(app.ArtifactResolver.Pattern()).To(Equal("target/paketo-modules/*"))
+				Expect(app.Arguments).To(Equal(arguments("--projects", "lib,app", "--also-make", "test-argument")))
+				Expect(app.Executor).To(Equal(maven.ModulesExecutor{
+					ApplicationPath:         ctx.Application.Path,
+					InterestingFileDetector: libbs.JARInterestingFileDetector{},
+					Modules: []maven.Module{
+						{Name: "lib", Path: "lib", Pattern: "lib/target/*.[ejw]ar", Artifact: "lib-1.0.0.jar"},
+						{Name: "app", Path: "app", Pattern: "app/target/*.[ejw]ar", Artifact: "app-1.0.0.war"},
+					},
+				}))
+
+				Expect(result.Processes).To(Equal([]libcnb.Process{
+					{Type: "lib", Command: fmt.Sprintf("java -jar %s", filepath.Join(ctx.Application.Path, "lib", "*.jar"))},
+				}))
+
+				md := app.LayerContributor.ExpectedMetadata.(map[string]interface{})
+				Expect(md["modules"]).To(Equal([]map[string]interface{}{
+					{"name": "lib", "module": "lib", "artifact-pattern": "lib/target/*.[ejw]ar", "artifact": "lib-1.0.0.jar"},
+					{"name": "app", "module": "app", "artifact-pattern": "app/target/*.[ejw]ar", "artifact": "app-1.0.0.war"},
+				}))
+			})
+		})
+
+		context("BP_MAVEN_BUILT_MODULE is set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "lib")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_MAVEN_BUILT_MODULE")).To(Succeed())
+			})
+
+			it("uses the configured module", func() {
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				app := result.Layers[1].(libbs.Application)
+				Expect(app.ArtifactResolver.Pattern()).To(Equal("lib/target/*.[ejw]ar"))
+				Expect(app.Arguments).To(Equal(arguments("--projects", "lib", "--also-make", "test-argument")))
+			})
+
+			it("does not add --projects if the user already specified it", func() {
+				Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "-pl app package")).To(Succeed())
+				defer os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")
+
+				result, err := mavenBuild.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("-pl", "app", "package")))
+			})
+
+			it("does not add --projects if the user already specified it with its value", func() {
+				for _, a := range []string{"--projects=app", "-plapp"} {
+					Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", a+" package")).To(Succeed())
+
+					result, err := mavenBuild.Build(ctx)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments(a, "package")))
+				}
+				Expect(os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")).To(Succeed())
+			})
+		})
+	})
+
+	context("BP_MAVEN_PROJECT_PATH is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", "services/shop")).To(Succeed())
+
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "services", "shop", "lib"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "services", "shop", "pom.xml"), []byte(`<project>
+  <packaging>pom</packaging>
+  <modules>
+    <module>lib</module>
+    <module>app</module>
+  </modules>
+</project>`), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "services", "shop", "lib", "pom.xml"), []byte(`<project>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+</project>`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "services", "shop", "app"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "services", "shop", "app", "pom.xml"), []byte(`<project>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <packaging>war</packaging>
+</project>`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_PROJECT_PATH")).To(Succeed())
+		})
+
+		it("runs Maven in the project", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("services/shop/app/target/*.[ejw]ar"))
+			Expect(app.Arguments).To(Equal(arguments("test-argument")))
+
+			Expect(app.Executor).To(Equal(maven.WorkingDirectoryExecutor{
+				Directory: filepath.Join(ctx.Application.Path, "services", "shop"),
+			}))
+		})
+
+		it("resolves modules relative to the project", func() {
+			Expect(os.Setenv("BP_MAVEN_BUILT_MODULE", "lib")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_BUILT_MODULE")
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("services/shop/lib/target/*.[ejw]ar"))
+			Expect(app.Arguments).To(Equal(arguments("--projects", "lib", "--also-make", "test-argument")))
+		})
+
+		it("prefixes the default artifact with the project", func() {
+			ctx.Buildpack.Metadata["configurations"] = append(ctx.Buildpack.Metadata["configurations"].([]map[string]interface{}),
+				map[string]interface{}{"name": "BP_MAVEN_BUILT_ARTIFACT", "default": "target/*.[ejw]ar"})
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "services", "shop", "pom.xml"), []byte(`<project>
+  <modelVersion>4.0.0</modelVersion>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("services/shop/target/*.[ejw]ar"))
+			Expect(app.Arguments).To(Equal(arguments("test-argument")))
+		})
+	})
+
+	context("BP_NATIVE_IMAGE is true", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_NATIVE_IMAGE", "true")).To(Succeed())
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+  <profiles>
+    <profile>
+      <id>native</id>
+      <build>
+        <plugins>
+          <plugin>
+            <groupId>org.graalvm.buildtools</groupId>
+            <artifactId>native-maven-plugin</artifactId>
+          </plugin>
+        </plugins>
+      </build>
+    </profile>
+  </profiles>
+</project>`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_NATIVE_IMAGE")).To(Succeed())
+		})
+
+		it("builds and restores the native image", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("test-argument", "-Pnative", "native:compile")))
+			Expect(app.ArtifactResolver.Pattern()).To(Equal("target/paketo-native/test-artifact.zip"))
+			Expect(app.Executor).To(Equal(maven.NativeExecutor{
+				ApplicationPath: ctx.Application.Path,
+				Archive:         "target/paketo-native/test-artifact.zip",
+				Executable:      "target/test-artifact",
+			}))
+
+			executable := filepath.Join(ctx.Application.Path, "test-artifact")
+			Expect(result.Processes).To(Equal([]libcnb.Process{
+				{Type: "native-image", Command: executable},
+				{Type: "web", Command: executable, Default: true},
+			}))
+		})
+
+		it("does not add the native profile twice", func() {
+			Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "-Pnative native:compile")).To(Succeed())
+			defer os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("-Pnative", "native:compile")))
+		})
+
+		it("does not build a native image without the native profile", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+</project>`), 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(libbs.Application).Arguments).To(Equal(arguments("test-argument")))
+			Expect(result.Processes).To(BeEmpty())
+		})
+	})
+
+	context("Node.js is on $PATH", func() {
+		var (
+			bin  string
+			path string
+		)
+
+		it.Before(func() {
+			var err error
+
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>com.github.eirslett</groupId>
+        <artifactId>frontend-maven-plugin</artifactId>
+        <executions>
+          <execution>
+            <goals><goal>install-node-and-yarn</goal></goals>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+			bin, err = ioutil.TempDir("", "build-bin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(bin, "node"), []byte{}, 0755)).To(Succeed())
+
+			path = os.Getenv("PATH")
+			Expect(os.Setenv("PATH", bin)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Setenv("PATH", path)).To(Succeed())
+			Expect(os.RemoveAll(bin)).To(Succeed())
+		})
+
+		it("skips the installation of Node.js", func() {
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("-Dskip.installnodenpm", "test-argument")))
+			Expect(app.Executor).To(Equal(maven.NodeExecutor{
+				ApplicationPath:    ctx.Application.Path,
+				InstallDirectories: []string{"."},
+				Logger:             mavenBuild.Logger,
+			}))
+		})
+
+		it("skips the installation of Yarn", func() {
+			Expect(ioutil.WriteFile(filepath.Join(bin, "yarn"), []byte{}, 0755)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("-Dskip.installnodenpm", "-Dskip.installyarn", "test-argument")))
+			Expect(app.Executor.(maven.NodeExecutor).Yarn).To(BeTrue())
+		})
+
+		it("installs Node.js without node on $PATH", func() {
+			Expect(os.Remove(filepath.Join(bin, "node"))).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			app := result.Layers[1].(libbs.Application)
+			Expect(app.Arguments).To(Equal(arguments("test-argument")))
+			Expect(app.Executor).To(BeNil())
+		})
+	})
+
+	context("BP_MAVEN_LOCKFILE_MODE is verify", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_LOCKFILE_MODE", "verify")).To(Succeed())
+			ctx.Buildpack.Metadata["configurations"] = append(ctx.Buildpack.Metadata["configurations"].([]map[string]interface{}),
+				map[string]interface{}{"name": "BP_MAVEN_LOCKFILE", "default": "maven.lock"})
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_LOCKFILE_MODE")).To(Succeed())
+		})
+
+		it("verifies the lock file when the application is built", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "maven.lock"), []byte{}, 0644)).To(Succeed())
+
+			result, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[1].Name()).To(Equal("application"))
+			Expect(result.Layers[1].(libbs.Application).Executor).To(Equal(maven.LockfileExecutor{
+				Lockfile: maven.Lockfile{
+					ApplicationPath: ctx.Application.Path,
+					Expected:        map[string]string{},
+					Logger:          mavenBuild.Logger,
+					Mode:            maven.LockfileModeVerify,
+					Path:            "maven.lock",
+					RepositoryPath:  filepath.Join(maven.MavenUserHome(ctx.Layers.Path), "repository"),
+				},
+			}))
+		})
+
+		it("warns that artifacts of the base repositories are not verified", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "maven.lock"), []byte{}, 0644)).To(Succeed())
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+			}
+			defer func() { ctx.Platform.Bindings = nil }()
+			buf := &bytes.Buffer{}
+			mavenBuild.Logger = bard.NewLogger(buf)
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring(
+				"WARNING: artifacts resolved from the base repositories /bindings/shared are not checked against the lock file"))
+		})
+
+		it("fails without lock file", func() {
+			Expect(ioutil.WriteFile(mvnwFilepath, []byte{}, 0644)).To(Succeed())
+
+			_, err := mavenBuild.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to open lock file")))
+		})
+	})
+
 	it("converts CRLF formatting in the mvnw file to LF (unix) if present", func() {
 		Expect(ioutil.WriteFile(mvnwFilepath, []byte("test\r\n"), 0644)).To(Succeed())
 		ctx.StackID = "test-stack-id"
@@ -455,7 +1710,7 @@ type FakeApplicationFactory struct{}
 func (f *FakeApplicationFactory) NewApplication(
 	additionalMetdata map[string]interface{},
 	argugments []string,
-	_ libbs.ArtifactResolver,
+	artifactResolver libbs.ArtifactResolver,
 	_ libbs.Cache,
 	command string,
 	_ *libcnb.BOM,
@@ -470,6 +1725,21 @@ func (f *FakeApplicationFactory) NewApplication(
 	return libbs.Application{
 		LayerContributor: contributor,
 		Arguments:        argugments,
+		ArtifactResolver: artifactResolver,
 		Command:          command,
 	}, nil
 }
+
+type FakeExecutor struct {
+	Executions []effect.Execution
+	Stdout     string
+	Err        error
+}
+
+func (f *FakeExecutor) Execute(execution effect.Execution) error {
+	f.Executions = append(f.Executions, execution)
+	if execution.Stdout != nil {
+		_, _ = execution.Stdout.Write([]byte(f.Stdout))
+	}
+	return f.Err
+}
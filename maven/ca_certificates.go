@@ -0,0 +1,203 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/bindings"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/pavel-v-chernykh/keystore-go/v4"
+)
+
+const (
+	// BindingTypeCACertificates is the type of bindings whose files are PEM encoded CA certificates to trust.
+	BindingTypeCACertificates = "ca-certificates"
+
+	// DefaultCertFile is the CA certificates of the system, trusted in addition to the bound certificates.
+	DefaultCertFile = "/etc/ssl/certs/ca-certificates.crt"
+
+	// TrustStoreFile is the name of the truststore in the CACertificates layer.
+	TrustStoreFile = "truststore.jks"
+
+	// TrustStorePassword is the password of the truststore, the default password of the JVM truststore.
+	TrustStorePassword = "changeit"
+)
+
+// normalizedDateTime is the creation time of truststore entries, so that the truststore is reproducible.
+var normalizedDateTime = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
+
+// BoundCertificates returns the files of the ca-certificates bindings, sorted by binding and file name.
+func BoundCertificates(binds libcnb.Bindings) []string {
+	var files []string
+
+	for _, b := range bindings.Resolve(binds, bindings.OfType(BindingTypeCACertificates)) {
+		var names []string
+		for name := range b.Secret {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if path, ok := b.SecretFilePath(name); ok {
+				files = append(files, path)
+			}
+		}
+	}
+
+	return files
+}
+
+// CACertificates contributes a layer containing a JKS truststore of the CA certificates of the system, $SSL_CERT_FILE
+// or DefaultCertFile, and of the bound certificates, so that the JVM running Maven trusts the repositories signed by
+// a private CA without a truststore configured in .mvn/jvm.config.
+type CACertificates struct {
+	CertFile     string
+	Certificates []string
+	Logger       bard.Logger
+}
+
+// NewCACertificates creates a new CACertificates instance trusting certificates, and the CA certificates of the system.
+func NewCACertificates(certificates []string) CACertificates {
+	c := CACertificates{CertFile: DefaultCertFile, Certificates: certificates}
+
+	if s, ok := os.LookupEnv("SSL_CERT_FILE"); ok && s != "" {
+		c.CertFile = s
+	}
+
+	return c
+}
+
+func (c CACertificates) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.RemoveAll(layer.Path); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", layer.Path, err)
+	}
+
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer directory %s\n%w", layer.Path, err)
+	}
+
+	ks := keystore.New(keystore.WithOrderedAliases())
+
+	if _, err := os.Stat(c.CertFile); os.IsNotExist(err) {
+		c.Logger.Bodyf("WARNING: unable to find CA certificates %s, trusting only the bound certificates", c.CertFile)
+	} else if _, err := c.add(ks, c.CertFile); err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	added := 0
+	for _, f := range c.Certificates {
+		n, err := c.add(ks, f)
+		if err != nil {
+			return libcnb.Layer{}, err
+		} else if n == 0 {
+			return libcnb.Layer{}, fmt.Errorf("unable to find certificates in %s", f)
+		}
+		added += n
+	}
+
+	file := filepath.Join(layer.Path, TrustStoreFile)
+	c.Logger.Bodyf("Adding %d bound CA certificates to truststore %s", added, file)
+
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer out.Close()
+
+	if err := ks.Store(out, []byte(TrustStorePassword)); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to encode truststore\n%w", err)
+	}
+
+	return layer, nil
+}
+
+// add adds the PEM encoded certificates of file to ks, returning the number of certificates added.
+func (CACertificates) add(ks keystore.KeyStore, file string) (int, error) {
+	rest, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	added := 0
+	for {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		entry := keystore.TrustedCertificateEntry{
+			CreationTime: normalizedDateTime,
+			Certificate: keystore.Certificate{
+				Type:    "X.509",
+				Content: block.Bytes,
+			},
+		}
+		if err := ks.SetTrustedCertificateEntry(fmt.Sprintf("%s-%d", file, added), entry); err != nil {
+			return 0, fmt.Errorf("unable to add certificate of %s\n%w", file, err)
+		}
+
+		added++
+	}
+
+	return added, nil
+}
+
+func (CACertificates) Name() string {
+	return "ca-certificates"
+}
+
+// TrustStoreOptions returns the JVM options configuring the truststore at trustStore.
+func TrustStoreOptions(trustStore string) string {
+	return fmt.Sprintf("-Djavax.net.ssl.trustStore=%s -Djavax.net.ssl.trustStorePassword=%s "+
+		"-Djavax.net.ssl.trustStoreType=JKS", trustStore, TrustStorePassword)
+}
+
+// TrustStoreExecutor is an effect.Executor that configures the JVM running Maven, through $MAVEN_OPTS, to use a
+// truststore.
+type TrustStoreExecutor struct {
+	Delegate   effect.Executor
+	TrustStore string
+}
+
+func (t TrustStoreExecutor) Execute(execution effect.Execution) error {
+	opts := TrustStoreOptions(t.TrustStore)
+
+	env := environ(execution)
+	for i, e := range env {
+		if strings.HasPrefix(e, "MAVEN_OPTS=") {
+			opts = fmt.Sprintf("%s %s", strings.TrimPrefix(e, "MAVEN_OPTS="), opts)
+			env = append(env[:i], env[i+1:]...)
+			break
+		}
+	}
+	execution.Env = append(env, fmt.Sprintf("MAVEN_OPTS=%s", strings.TrimSpace(opts)))
+
+	return t.Delegate.Execute(execution)
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/pavel-v-chernykh/keystore-go/v4"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testCACertificates(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "ca-certificates")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	// certificate writes count self-signed CA certificates, PEM encoded, to file
+	certificate := func(file string, count int) {
+		var out []byte
+		for i := 0; i < count; i++ {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			template := x509.Certificate{
+				SerialNumber:          big.NewInt(int64(i + 1)),
+				Subject:               pkix.Name{CommonName: fmt.Sprintf("Test CA %d", i)},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(time.Hour),
+				IsCA:                  true,
+				BasicConstraintsValid: true,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+			Expect(err).NotTo(HaveOccurred())
+
+			out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+		}
+
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(file, out, 0644)).To(Succeed())
+	}
+
+	it("returns the files of ca-certificates bindings", func() {
+		Expect(maven.BoundCertificates(libcnb.Bindings{
+			{
+				Name:   "corporate",
+				Type:   "ca-certificates",
+				Secret: map[string]string{"root.pem": "", "intermediate.pem": ""},
+				Path:   "/bindings/corporate",
+			},
+			{Name: "settings", Type: "maven", Secret: map[string]string{"settings.xml": ""}, Path: "/bindings/settings"},
+		})).To(Equal([]string{"/bindings/corporate/intermediate.pem", "/bindings/corporate/root.pem"}))
+	})
+
+	context("CACertificates", func() {
+		var layer libcnb.Layer
+
+		it.Before(func() {
+			layer.Path = filepath.Join(path, "layer")
+		})
+
+		it("writes a truststore of the system and bound certificates", func() {
+			certificate(filepath.Join(path, "system.crt"), 2)
+			certificate(filepath.Join(path, "bindings", "corporate.pem"), 1)
+
+			layer, err := maven.CACertificates{
+				CertFile:     filepath.Join(path, "system.crt"),
+				Certificates: []string{filepath.Join(path, "bindings", "corporate.pem")},
+			}.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			in, err := os.Open(filepath.Join(layer.Path, "truststore.jks"))
+			Expect(err).NotTo(HaveOccurred())
+			defer in.Close()
+
+			ks := keystore.New()
+			Expect(ks.Load(in, []byte("changeit"))).To(Succeed())
+			Expect(ks.Aliases()).To(HaveLen(3))
+		})
+
+		it("trusts only the bound certificates without system certificates", func() {
+			certificate(filepath.Join(path, "bindings", "corporate.pem"), 1)
+
+			layer, err := maven.CACertificates{
+				CertFile:     filepath.Join(path, "missing.crt"),
+				Certificates: []string{filepath.Join(path, "bindings", "corporate.pem")},
+			}.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			in, err := os.Open(filepath.Join(layer.Path, "truststore.jks"))
+			Expect(err).NotTo(HaveOccurred())
+			defer in.Close()
+
+			ks := keystore.New()
+			Expect(ks.Load(in, []byte("changeit"))).To(Succeed())
+			Expect(ks.Aliases()).To(HaveLen(1))
+		})
+
+		it("fails if a bound file contains no certificates", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "empty.pem"), []byte("not a certificate"), 0644)).To(Succeed())
+
+			_, err := maven.CACertificates{
+				CertFile:     filepath.Join(path, "missing.crt"),
+				Certificates: []string{filepath.Join(path, "empty.pem")},
+			}.Contribute(layer)
+			Expect(err).To(MatchError(fmt.Sprintf("unable to find certificates in %s", filepath.Join(path, "empty.pem"))))
+		})
+	})
+
+	context("TrustStoreExecutor", func() {
+		var delegate *FakeExecutor
+
+		it.Before(func() {
+			delegate = &FakeExecutor{}
+		})
+
+		it("adds the truststore to $MAVEN_OPTS", func() {
+			Expect(maven.TrustStoreExecutor{Delegate: delegate, TrustStore: "/layers/ca-certificates/truststore.jks"}.
+				Execute(effect.Execution{Env: []string{"MAVEN_OPTS=-Xmx1g", "TEST_KEY=test-value"}})).To(Succeed())
+
+			Expect(delegate.Executions[0].Env).To(Equal([]string{
+				"TEST_KEY=test-value",
+				"MAVEN_OPTS=-Xmx1g -Djavax.net.ssl.trustStore=/layers/ca-certificates/truststore.jks " +
+					"-Djavax.net.ssl.trustStorePassword=changeit -Djavax.net.ssl.trustStoreType=JKS",
+			}))
+		})
+
+		it("sets $MAVEN_OPTS", func() {
+			Expect(maven.TrustStoreExecutor{Delegate: delegate, TrustStore: "/layers/ca-certificates/truststore.jks"}.
+				Execute(effect.Execution{Env: []string{"TEST_KEY=test-value"}})).To(Succeed())
+
+			Expect(delegate.Executions[0].Env).To(ContainElement(
+				"MAVEN_OPTS=-Djavax.net.ssl.trustStore=/layers/ca-certificates/truststore.jks " +
+					"-Djavax.net.ssl.trustStorePassword=changeit -Djavax.net.ssl.trustStoreType=JKS"))
+		})
+	})
+}
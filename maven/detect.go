@@ -34,38 +34,123 @@ const (
 	PlanEntrySyft                  = "syft"
 )
 
-type Detect struct{}
+const (
+	DetectModeAuto  = "auto"
+	DetectModeForce = "force"
+	DetectModeNever = "never"
+)
+
+type Detect struct {
+	Logger bard.Logger
+}
 
-func (Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error) {
+func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error) {
 	l := bard.NewLogger(ioutil.Discard)
 	cr, err := libpak.NewConfigurationResolver(context.Buildpack, &l)
 	if err != nil {
 		return libcnb.DetectResult{}, err
 	}
 
-	pomFile, _ := cr.Resolve("BP_MAVEN_POM_FILE")
+	mode, _ := cr.Resolve("BP_MAVEN_DETECT_MODE")
+	switch mode {
+	case DetectModeNever:
+		return libcnb.DetectResult{Pass: false}, nil
+	case "", DetectModeAuto, DetectModeForce:
+	default:
+		return libcnb.DetectResult{}, fmt.Errorf("unknown detect mode %s, must be one of %s, %s or %s",
+			mode, DetectModeAuto, DetectModeForce, DetectModeNever)
+	}
+
+	project, err := ProjectPath(cr, context.Application.Path)
+	if m, ok := err.(MultiplePOMsError); ok {
+		d.Logger.Info(m.Error())
+		return libcnb.DetectResult{Pass: false}, nil
+	} else if err != nil {
+		return libcnb.DetectResult{}, fmt.Errorf("unable to resolve project path\n%w", err)
+	}
+
+	pomFile, pomFileSet := cr.Resolve("BP_MAVEN_POM_FILE")
+	if !pomFileSet {
+		pomFile = filepath.Join(project, pomFile)
+	}
 	file := filepath.Join(context.Application.Path, pomFile)
+	polyglot := false
 	_, err = os.Stat(file)
 	if os.IsNotExist(err) {
-		return libcnb.DetectResult{Pass: false}, nil
+		if pomFileSet {
+			return libcnb.DetectResult{Pass: false}, nil
+		}
+
+		if _, ok, err := PolyglotPOM(filepath.Join(context.Application.Path, project)); err != nil {
+			return libcnb.DetectResult{}, fmt.Errorf("unable to find polyglot POM\n%w", err)
+		} else if !ok {
+			return libcnb.DetectResult{Pass: false}, nil
+		}
+		polyglot = true
 	} else if err != nil {
 		return libcnb.DetectResult{}, fmt.Errorf("unable to determine if %s exists\n%w", file, err)
 	}
 
-	return libcnb.DetectResult{
-		Pass: true,
-		Plans: []libcnb.BuildPlan{
-			{
-				Provides: []libcnb.BuildPlanProvide{
-					{Name: PlanEntryJVMApplicationPackage},
-					{Name: PlanEntryMaven},
-				},
-				Requires: []libcnb.BuildPlanRequire{
-					{Name: PlanEntrySyft},
-					{Name: PlanEntryJDK},
-					{Name: PlanEntryMaven},
-				},
-			},
+	// the modules of a polyglot POM cannot be read, so that it is assumed to have sources
+	if mode != DetectModeForce && !polyglot {
+		// a stray POM beside a prebuilt artifact is left to the buildpacks handling the artifact
+		// a reactor that cannot be read may have sources, Maven reporting its errors rather than failing the detection
+		// of the whole group
+		if ok, err := HasSources(context.Application.Path, pomFile); err != nil {
+			d.Logger.Infof("Unable to find sources, assuming the project has sources\n%s", err)
+		} else if !ok {
+			return libcnb.DetectResult{Pass: false}, nil
+		}
+	}
+
+	maven := libcnb.BuildPlanRequire{Name: PlanEntryMaven}
+
+	// a POM that cannot be read is reported by the build, the version defaulting to Maven 3
+	pom, _ := ReadPOM(file)
+	if version := MavenVersion(cr, filepath.Join(context.Application.Path, project), pom); IsMaven4(version) {
+		maven.Metadata = map[string]interface{}{"version": version}
+	}
+	if framework, ok, err := ReactorFramework(context.Application.Path, pomFile); err == nil && ok {
+		if maven.Metadata == nil {
+			maven.Metadata = map[string]interface{}{}
+		}
+		maven.Metadata["framework"] = framework
+	}
+
+	plan := libcnb.BuildPlan{
+		Provides: []libcnb.BuildPlanProvide{
+			{Name: PlanEntryJVMApplicationPackage},
+			{Name: PlanEntryMaven},
+		},
+		Requires: []libcnb.BuildPlanRequire{
+			{Name: PlanEntrySyft},
+			{Name: PlanEntryJDK},
+			maven,
 		},
-	}, nil
+	}
+
+	if cr.ResolveBool("BP_NATIVE_IMAGE") {
+		if _, ok := NativeImage(pom); ok {
+			plan.Requires = append(plan.Requires, libcnb.BuildPlanRequire{Name: PlanEntryNativeImageBuilder})
+		}
+	}
+
+	result := libcnb.DetectResult{Pass: true}
+
+	// Node.js is optional, the build falling back to the tools downloaded by Maven plugins
+	if node, err := NewNode(context.Application.Path, pomFile); err == nil && node.Node {
+		withNode := libcnb.BuildPlan{
+			Provides: plan.Provides,
+			Requires: append(append([]libcnb.BuildPlanRequire{}, plan.Requires...),
+				libcnb.BuildPlanRequire{Name: PlanEntryNode, Metadata: map[string]interface{}{"build": true}}),
+		}
+		if node.Yarn {
+			withNode.Requires = append(withNode.Requires,
+				libcnb.BuildPlanRequire{Name: PlanEntryYarn, Metadata: map[string]interface{}{"build": true}})
+		}
+		result.Plans = append(result.Plans, withNode)
+	}
+
+	result.Plans = append(result.Plans, plan)
+	return result, nil
 }
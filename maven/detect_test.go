@@ -17,6 +17,7 @@
 package maven_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/buildpacks/libcnb"
 	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/sclevine/spec"
 
 	"github.com/paketo-buildpacks/maven/v6/maven"
@@ -42,6 +44,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 
 		ctx.Application.Path, err = ioutil.TempDir("", "maven")
 		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "src", "main", "java"), 0755)).To(Succeed())
 	})
 
 	it.After(func() {
@@ -96,4 +99,293 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			},
 		}))
 	})
+
+	it("requires Maven 4 for a 4.1.0 model", func() {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"),
+			[]byte(`<project><modelVersion>4.1.0</modelVersion></project>`), 0644)).To(Succeed())
+		os.Setenv("BP_MAVEN_POM_FILE", "pom.xml")
+
+		result, err := detect.Detect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Plans[0].Requires[2]).To(Equal(libcnb.BuildPlanRequire{
+			Name:     "maven",
+			Metadata: map[string]interface{}{"version": "4"},
+		}))
+	})
+
+	it("publishes the framework", func() {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+  </parent>
+</project>`), 0644)).To(Succeed())
+		os.Setenv("BP_MAVEN_POM_FILE", "pom.xml")
+
+		result, err := detect.Detect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Plans[0].Requires[2]).To(Equal(libcnb.BuildPlanRequire{
+			Name:     "maven",
+			Metadata: map[string]interface{}{"framework": "spring-boot"},
+		}))
+	})
+
+	context("polyglot POM", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"configurations": []map[string]interface{}{
+					{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+				},
+			}
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, ".mvn"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, ".mvn", "extensions.xml"), []byte(`<extensions>
+  <extension>
+    <groupId>io.takari.polyglot</groupId>
+    <artifactId>polyglot-yaml</artifactId>
+    <version>0.4.8</version>
+  </extension>
+</extensions>`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.Buildpack.Metadata = nil
+		})
+
+		it("passes with pom.yaml", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.yaml"), []byte{}, 0644)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("passes with pom.yaml and sources only in a module", func() {
+			Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "src"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "app", "src"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.yaml"), []byte(`modules:
+  - app
+`), 0644)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("fails without a polyglot POM", func() {
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+		})
+	})
+
+	context("BP_MAVEN_DETECT_MODE", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"configurations": []map[string]interface{}{
+					{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+				},
+			}
+
+			Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "src"))).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "app.jar"), []byte{}, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <modules>
+    <module>app</module>
+  </modules>
+</project>`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.Buildpack.Metadata = nil
+			Expect(os.Unsetenv("BP_MAVEN_DETECT_MODE")).To(Succeed())
+		})
+
+		it("fails without sources", func() {
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+		})
+
+		it("passes with module sources", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "app", "src"), 0755)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("passes with sources in a POM that is not UTF-8", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(
+				"<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n"+
+					"<project><name>caf\xe9</name><modules><module>app</module></modules></project>"), 0644)).To(Succeed())
+
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "app", "src"), 0755)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("passes with a POM that cannot be read", func() {
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte("<project>"), 0644)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("passes without sources if forced", func() {
+			Expect(os.Setenv("BP_MAVEN_DETECT_MODE", "force")).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("fails with sources if never", func() {
+			Expect(os.Setenv("BP_MAVEN_DETECT_MODE", "never")).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "src"), 0755)).To(Succeed())
+
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+		})
+
+		it("fails with an unknown mode", func() {
+			Expect(os.Setenv("BP_MAVEN_DETECT_MODE", "test-mode")).To(Succeed())
+
+			_, err := detect.Detect(ctx)
+			Expect(err).To(MatchError("unknown detect mode test-mode, must be one of auto, force or never"))
+		})
+	})
+
+	context("BP_MAVEN_POM_DISCOVERY_DEPTH is set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+			Expect(os.Setenv("BP_MAVEN_POM_DISCOVERY_DEPTH", "2")).To(Succeed())
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"configurations": []map[string]interface{}{
+					{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+				},
+			}
+
+			for _, s := range []string{"billing", "shop"} {
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "services", s, "src"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "services", s, "pom.xml"), []byte{}, 0644)).To(Succeed())
+			}
+		})
+
+		it.After(func() {
+			ctx.Buildpack.Metadata = nil
+			Expect(os.Unsetenv("BP_MAVEN_POM_DISCOVERY_DEPTH")).To(Succeed())
+		})
+
+		it("passes with a single POM", func() {
+			Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "services", "billing"))).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("fails with several POMs", func() {
+			b := &bytes.Buffer{}
+			detect.Logger = bard.NewLogger(b)
+
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+			Expect(b.String()).To(ContainSubstring("found multiple POMs [services/billing/pom.xml services/shop/pom.xml]"))
+		})
+	})
+
+	it("requires a native image builder for the native profile if BP_NATIVE_IMAGE is true", func() {
+		Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+		Expect(os.Setenv("BP_NATIVE_IMAGE", "true")).To(Succeed())
+		defer os.Unsetenv("BP_NATIVE_IMAGE")
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"configurations": []map[string]interface{}{
+				{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+			},
+		}
+		defer func() { ctx.Buildpack.Metadata = nil }()
+
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <artifactId>test-artifact</artifactId>
+  <profiles>
+    <profile>
+      <id>native</id>
+      <build>
+        <plugins>
+          <plugin>
+            <groupId>org.graalvm.buildtools</groupId>
+            <artifactId>native-maven-plugin</artifactId>
+          </plugin>
+        </plugins>
+      </build>
+    </profile>
+  </profiles>
+</project>`), 0644)).To(Succeed())
+
+		result, err := detect.Detect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{
+			{Name: "syft"},
+			{Name: "jdk"},
+			{Name: "maven"},
+			{Name: "native-image-builder"},
+		}))
+	})
+
+	it("optionally requires Node.js for the frontend-maven-plugin", func() {
+		Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"configurations": []map[string]interface{}{
+				{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+			},
+		}
+		defer func() { ctx.Buildpack.Metadata = nil }()
+
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(`<project>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>com.github.eirslett</groupId>
+        <artifactId>frontend-maven-plugin</artifactId>
+        <executions>
+          <execution>
+            <goals><goal>install-node-and-yarn</goal></goals>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+		Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{
+			Pass: true,
+			Plans: []libcnb.BuildPlan{
+				{
+					Provides: []libcnb.BuildPlanProvide{
+						{Name: "jvm-application-package"},
+						{Name: "maven"},
+					},
+					Requires: []libcnb.BuildPlanRequire{
+						{Name: "syft"},
+						{Name: "jdk"},
+						{Name: "maven"},
+						{Name: "node", Metadata: map[string]interface{}{"build": true}},
+						{Name: "yarn", Metadata: map[string]interface{}{"build": true}},
+					},
+				},
+				{
+					Provides: []libcnb.BuildPlanProvide{
+						{Name: "jvm-application-package"},
+						{Name: "maven"},
+					},
+					Requires: []libcnb.BuildPlanRequire{
+						{Name: "syft"},
+						{Name: "jdk"},
+						{Name: "maven"},
+					},
+				},
+			},
+		}))
+	})
 }
@@ -29,6 +29,7 @@ import (
 type Distribution struct {
 	LayerContributor libpak.DependencyLayerContributor
 	Logger           bard.Logger
+	Verifier         *SignatureVerifier
 }
 
 func NewDistribution(dependency libpak.BuildpackDependency, cache libpak.DependencyCache) (Distribution, libcnb.BOMEntry) {
@@ -42,6 +43,13 @@ func (d Distribution) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	d.LayerContributor.Logger = d.Logger
 
 	return d.LayerContributor.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+		if d.Verifier != nil {
+			d.Verifier.Logger = d.Logger
+			if err := d.Verifier.Verify(artifact); err != nil {
+				return libcnb.Layer{}, err
+			}
+		}
+
 		d.Logger.Bodyf("Expanding to %s", layer.Path)
 		if err := crush.ExtractTarGz(artifact, layer.Path, 1); err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to expand Maven\n%w", err)
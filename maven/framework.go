@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FrameworkHelidon    = "helidon"
+	FrameworkMicronaut  = "micronaut"
+	FrameworkQuarkus    = "quarkus"
+	FrameworkSpringBoot = "spring-boot"
+)
+
+// frameworks maps each framework to the groupIds of its parent POMs, BOMs and Maven plugins.
+var frameworks = []struct {
+	Name     string
+	GroupIDs []string
+}{
+	{Name: FrameworkSpringBoot, GroupIDs: []string{"org.springframework.boot"}},
+	{Name: FrameworkQuarkus, GroupIDs: []string{"io.quarkus", "io.quarkus.platform"}},
+	{Name: FrameworkMicronaut, GroupIDs: []string{"io.micronaut", "io.micronaut.platform", "io.micronaut.maven"}},
+	{Name: FrameworkHelidon, GroupIDs: []string{"io.helidon", "io.helidon.applications", "io.helidon.build-tools"}},
+}
+
+// QuarkusArtifactPatterns are the patterns, relative to the module, of the fast-jar layout built by Quarkus in
+// target/quarkus-app, restoring its contents to the application root.
+var QuarkusArtifactPatterns = []string{
+	"target/quarkus-app/lib",
+	"target/quarkus-app/*.jar",
+	"target/quarkus-app/app",
+	"target/quarkus-app/quarkus",
+}
+
+// Framework returns the application framework that a POM uses through its parent, an imported BOM or a Maven plugin.
+// Returns false if the POM does not use a known framework.
+func (p POM) Framework() (string, bool) {
+	for _, f := range frameworks {
+		for _, g := range f.GroupIDs {
+			if p.Parent.GroupID == g {
+				return f.Name, true
+			}
+
+			for _, d := range p.DependencyManagement {
+				if d.GroupID == g && d.Type == "pom" && d.Scope == "import" {
+					return f.Name, true
+				}
+			}
+
+			for _, plugin := range p.Plugins {
+				if plugin.GroupID == g {
+					return f.Name, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ReactorFramework returns the first application framework used by a project in the reactor rooted at pomFile.
+func ReactorFramework(applicationPath string, pomFile string) (string, bool, error) {
+	framework := ""
+
+	if _, err := walkReactor(applicationPath, pomFile, func(_ string, pom POM) {
+		if framework != "" {
+			return
+		}
+		framework, _ = pom.Framework()
+	}); err != nil {
+		return "", false, err
+	}
+
+	return framework, framework != "", nil
+}
+
+// QuarkusArtifactPattern returns the space separated patterns, relative to the module, of the artifact built by
+// Quarkus: the runner jar if the POM configures an uber-jar, and the contents of the fast-jar layout otherwise.
+func QuarkusArtifactPattern(pom POM, prefix string) string {
+	for _, k := range []string{"quarkus.package.type", "quarkus.package.jar.type"} {
+		if pom.Properties[k] == "uber-jar" {
+			return filepath.Join(prefix, "target", "*-runner.jar")
+		}
+	}
+
+	var patterns []string
+	for _, p := range QuarkusArtifactPatterns {
+		patterns = append(patterns, filepath.Join(prefix, p))
+	}
+
+	return strings.Join(patterns, " ")
+}
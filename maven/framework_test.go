@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testFramework(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "framework")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	context("Framework", func() {
+		it("does not detect a framework by default", func() {
+			_, ok := maven.POM{}.Framework()
+			Expect(ok).To(BeFalse())
+		})
+
+		it("detects Spring Boot from the parent", func() {
+			framework, ok := maven.POM{
+				Parent: maven.Parent{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-parent"},
+			}.Framework()
+			Expect(ok).To(BeTrue())
+			Expect(framework).To(Equal("spring-boot"))
+		})
+
+		it("detects Spring Boot from an imported BOM", func() {
+			framework, ok := maven.POM{
+				DependencyManagement: []maven.Dependency{
+					{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-dependencies", Type: "pom", Scope: "import"},
+				},
+			}.Framework()
+			Expect(ok).To(BeTrue())
+			Expect(framework).To(Equal("spring-boot"))
+		})
+
+		it("does not detect a framework from a managed dependency", func() {
+			_, ok := maven.POM{
+				DependencyManagement: []maven.Dependency{
+					{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-web"},
+				},
+			}.Framework()
+			Expect(ok).To(BeFalse())
+		})
+
+		it("detects Quarkus from the plugin", func() {
+			framework, ok := maven.POM{
+				Plugins: []maven.Plugin{{GroupID: "io.quarkus.platform", ArtifactID: "quarkus-maven-plugin"}},
+			}.Framework()
+			Expect(ok).To(BeTrue())
+			Expect(framework).To(Equal("quarkus"))
+		})
+
+		it("detects Micronaut from the parent", func() {
+			framework, ok := maven.POM{
+				Parent: maven.Parent{GroupID: "io.micronaut.platform", ArtifactID: "micronaut-parent"},
+			}.Framework()
+			Expect(ok).To(BeTrue())
+			Expect(framework).To(Equal("micronaut"))
+		})
+
+		it("detects Helidon from the parent", func() {
+			framework, ok := maven.POM{
+				Parent: maven.Parent{GroupID: "io.helidon.applications", ArtifactID: "helidon-se"},
+			}.Framework()
+			Expect(ok).To(BeTrue())
+			Expect(framework).To(Equal("helidon"))
+		})
+	})
+
+	it("detects the framework of a module", func() {
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.xml"), []byte(`<project>
+  <modules><module>app</module></modules>
+</project>`), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "app"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, "app", "pom.xml"), []byte(`<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+  </parent>
+</project>`), 0644)).To(Succeed())
+
+		framework, ok, err := maven.ReactorFramework(path, "pom.xml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(framework).To(Equal("spring-boot"))
+	})
+
+	context("QuarkusArtifactPattern", func() {
+		it("selects the fast-jar layout", func() {
+			Expect(maven.QuarkusArtifactPattern(maven.POM{}, "app")).To(Equal(
+				"app/target/quarkus-app/lib app/target/quarkus-app/*.jar app/target/quarkus-app/app app/target/quarkus-app/quarkus"))
+		})
+
+		it("selects the uber-jar", func() {
+			Expect(maven.QuarkusArtifactPattern(maven.POM{
+				Properties: maven.Properties{"quarkus.package.type": "uber-jar"},
+			}, "")).To(Equal("target/*-runner.jar"))
+		})
+	})
+}
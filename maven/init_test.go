@@ -25,9 +25,30 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("maven", spec.Report(report.Terminal{}))
+	suite("Artifact", testArtifact)
 	suite("Build", testBuild)
+	suite("CACertificates", testCACertificates)
 	suite("Detect", testDetect)
 	suite("Distribution", testDistribution)
+	suite("Framework", testFramework)
+	suite("Integration", testIntegration)
+	suite("Lockfile", testLockfile)
+	suite("Modules", testModules)
+	suite("MvndDaemon", testMvndDaemon)
 	suite("MvndDistribution", testMvndDistribution)
+	suite("Native", testNative)
+	suite("Node", testNode)
+	suite("POM", testPOM)
+	suite("Polyglot", testPolyglot)
+	suite("Project", testProject)
+	suite("Proxy", testProxy)
+	suite("Reactor", testReactor)
+	suite("Repository", testRepository)
+	suite("Reproducible", testReproducible)
+	suite("Retry", testRetry)
+	suite("Signature", testSignature)
+	suite("SpringBoot", testSpringBoot)
+	suite("Timing", testTiming)
+	suite("Version", testVersion)
 	suite.Run(t)
 }
@@ -0,0 +1,385 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testIntegration(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx        libcnb.BuildContext
+		mavenBuild maven.Build
+		output     *bytes.Buffer
+		path       string
+		repository *FakeRepository
+		server     *httptest.Server
+	)
+
+	// build contributes the layers of a build in order, as the lifecycle does
+	build := func() error {
+		result, err := mavenBuild.Build(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, contributor := range result.Layers {
+			if app, ok := contributor.(libbs.Application); ok {
+				app.SBOMScanner = FakeSBOMScanner{}
+				contributor = app
+			}
+
+			layer, err := ctx.Layers.Layer(contributor.Name())
+			if err != nil {
+				return err
+			}
+
+			if _, err := contributor.Contribute(layer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	writeSettings := func(settings string) {
+		file := filepath.Join(path, "bindings", "settings", "settings.xml")
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(file, []byte(settings), 0644)).To(Succeed())
+
+		ctx.Platform.Bindings = libcnb.Bindings{{
+			Name:   "settings",
+			Type:   "maven",
+			Path:   filepath.Dir(file),
+			Secret: map[string]string{"settings.xml": settings},
+		}}
+	}
+
+	writePOM := func(repositoryURL string) {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte(fmt.Sprintf(`<project>
+  <groupId>org.example</groupId>
+  <artifactId>stub-app</artifactId>
+  <version>1.0.0</version>
+  <repositories>
+    <repository>
+      <id>example</id>
+      <url>%s</url>
+    </repository>
+  </repositories>
+  <dependencies>
+    <dependency>
+      <groupId>org.example</groupId>
+      <artifactId>greeting</artifactId>
+      <version>1.0.0</version>
+    </dependency>
+  </dependencies>
+</project>`, repositoryURL)), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "integration")
+		Expect(err).NotTo(HaveOccurred())
+
+		distribution, err := StubMavenDistribution()
+		Expect(err).NotTo(HaveOccurred())
+		sha := sha256.Sum256(distribution)
+
+		repository = &FakeRepository{Distribution: distribution, Root: filepath.Join("testdata", "repository")}
+		server = httptest.NewServer(repository)
+
+		for _, d := range []string{"application", "buildpack", "layers"} {
+			Expect(os.MkdirAll(filepath.Join(path, d), 0755)).To(Succeed())
+		}
+		ctx.Application.Path = filepath.Join(path, "application")
+		ctx.Buildpack.Path = filepath.Join(path, "buildpack")
+		ctx.Layers.Path = filepath.Join(path, "layers")
+		ctx.StackID = "test-stack-id"
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"configurations": []map[string]interface{}{
+				{"name": "BP_MAVEN_BUILD_ARGUMENTS", "default": "package"},
+				{"name": "BP_MAVEN_POM_FILE", "default": "pom.xml"},
+			},
+			"dependencies": []map[string]interface{}{
+				{
+					"id":      "maven",
+					"version": "3.9.0",
+					"stacks":  []interface{}{"test-stack-id"},
+					"uri":     fmt.Sprintf("%s/distribution/apache-maven-stub.tar.gz", server.URL),
+					"sha256":  hex.EncodeToString(sha[:]),
+				},
+			},
+		}
+
+		Expect(os.Setenv("BP_MAVEN_BUILT_ARTIFACT", "target/app")).To(Succeed())
+		Expect(os.Setenv("MAVEN_USER_HOME", filepath.Join(path, "m2"))).To(Succeed())
+
+		output = &bytes.Buffer{}
+		mavenBuild = maven.Build{
+			ApplicationFactory: &libbs.ApplicationFactory{Executor: StubJavacExecutor{Delegate: effect.CommandExecutor{}}},
+			Logger:             bard.NewLogger(output),
+		}
+
+		writePOM(fmt.Sprintf("%s/repository", server.URL))
+	})
+
+	it.After(func() {
+		server.Close()
+		ctx.Platform.Bindings = nil
+		Expect(os.Unsetenv("BP_MAVEN_BUILT_ARTIFACT")).To(Succeed())
+		Expect(os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")).To(Succeed())
+		Expect(os.Unsetenv("MAVEN_USER_HOME")).To(Succeed())
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("resolves dependencies from the repository of the POM", func() {
+		Expect(build()).To(Succeed())
+
+		Expect(repository.Requests()).To(ContainElement("/repository/org/example/greeting/1.0.0/greeting-1.0.0.jar"))
+		Expect(filepath.Join(path, "m2", "repository", "org", "example", "greeting", "1.0.0", "greeting-1.0.0.jar")).
+			To(BeARegularFile())
+		Expect(ioutil.ReadFile(filepath.Join(ctx.Application.Path, "app", "lib", "greeting-1.0.0.jar"))).
+			To(Equal(repository.Artifact("org/example/greeting/1.0.0/greeting-1.0.0.jar")))
+	})
+
+	it("resolves dependencies from the mirror of the settings binding", func() {
+		writePOM("http://127.0.0.1:1/unreachable")
+		writeSettings(fmt.Sprintf(`<settings>
+  <mirrors>
+    <mirror>
+      <id>mirror</id>
+      <mirrorOf>*</mirrorOf>
+      <url>%s/repository</url>
+    </mirror>
+  </mirrors>
+</settings>`, server.URL))
+
+		Expect(build()).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("Downloading from mirror"))
+		Expect(filepath.Join(ctx.Application.Path, "app", "lib", "greeting-1.0.0.jar")).To(BeARegularFile())
+	})
+
+	context("the repository requires authentication", func() {
+		it.Before(func() {
+			repository.Username, repository.Password = "test-username", "test-password"
+		})
+
+		it("authenticates with the credentials of the settings binding", func() {
+			writeSettings(`<settings>
+  <servers>
+    <server>
+      <id>example</id>
+      <username>test-username</username>
+      <password>test-password</password>
+    </server>
+  </servers>
+</settings>`)
+
+			Expect(build()).To(Succeed())
+			Expect(filepath.Join(ctx.Application.Path, "app", "lib", "greeting-1.0.0.jar")).To(BeARegularFile())
+		})
+
+		it("fails without credentials", func() {
+			Expect(build()).To(MatchError(ContainSubstring("error running build")))
+			Expect(output.String()).To(ContainSubstring("status code: 401, reason phrase: Unauthorized"))
+		})
+	})
+
+	context("offline", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "--offline package")).To(Succeed())
+		})
+
+		it("fails if a dependency has not been downloaded before", func() {
+			Expect(build()).To(MatchError(ContainSubstring("error running build")))
+			Expect(output.String()).To(ContainSubstring(
+				"artifact org.example:greeting:1.0.0 has not been downloaded from it before"))
+			Expect(repository.Requests()).To(BeEmpty())
+		})
+
+		it("resolves dependencies from the cache", func() {
+			Expect(os.Unsetenv("BP_MAVEN_BUILD_ARGUMENTS")).To(Succeed())
+			Expect(build()).To(Succeed())
+
+			// the source is removed by the build
+			writePOM(fmt.Sprintf("%s/repository", server.URL))
+			Expect(os.Setenv("BP_MAVEN_BUILD_ARGUMENTS", "--offline package")).To(Succeed())
+			downloads := len(repository.Requests())
+
+			Expect(build()).To(Succeed())
+			Expect(repository.Requests()).To(HaveLen(downloads))
+			Expect(filepath.Join(ctx.Application.Path, "app", "lib", "greeting-1.0.0.jar")).To(BeARegularFile())
+		})
+	})
+}
+
+// FakeRepository is a Maven repository serving the fixture tree at Root under /repository, optionally requiring basic
+// authentication, and the stub Maven distribution under /distribution.
+type FakeRepository struct {
+	Distribution []byte
+	Password     string
+	Root         string
+	Username     string
+
+	mutex    sync.Mutex
+	requests []string
+}
+
+func (f *FakeRepository) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/distribution/"):
+		_, _ = w.Write(f.Distribution)
+	case strings.HasPrefix(r.URL.Path, "/repository/"):
+		f.mutex.Lock()
+		f.requests = append(f.requests, r.URL.Path)
+		f.mutex.Unlock()
+
+		if f.Username != "" {
+			if u, p, ok := r.BasicAuth(); !ok || u != f.Username || p != f.Password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		http.StripPrefix("/repository", http.FileServer(http.Dir(f.Root))).ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Artifact returns the contents of an artifact in the fixture tree.
+func (f *FakeRepository) Artifact(path string) []byte {
+	b, err := ioutil.ReadFile(filepath.Join(f.Root, path))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Requests returns the paths requested from the repository, excluding the distribution.
+func (f *FakeRepository) Requests() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]string{}, f.requests...)
+}
+
+var stubMaven struct {
+	sync.Once
+	distribution []byte
+	err          error
+}
+
+// StubMavenDistribution builds the stub of mvn in testdata/stub-maven, once, and returns a Maven distribution
+// containing it.
+func StubMavenDistribution() ([]byte, error) {
+	stubMaven.Do(func() {
+		stubMaven.distribution, stubMaven.err = stubMavenDistribution()
+	})
+	return stubMaven.distribution, stubMaven.err
+}
+
+func stubMavenDistribution() ([]byte, error) {
+	path, err := ioutil.TempDir("", "stub-maven")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(path)
+
+	mvn := filepath.Join(path, "mvn")
+	if out, err := exec.Command("go", "build", "-o", mvn, "./testdata/stub-maven").CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("unable to build stub Maven\n%s\n%w", out, err)
+	}
+
+	b, err := ioutil.ReadFile(mvn)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+	gz := gzip.NewWriter(buffer)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{Name: "apache-maven-stub/bin/mvn", Mode: 0755, Size: int64(len(b))}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// StubJavacExecutor is an effect.Executor that reports the version of javac, rather than requiring a JDK, and runs
+// other commands with Delegate.
+type StubJavacExecutor struct {
+	Delegate effect.Executor
+}
+
+func (s StubJavacExecutor) Execute(execution effect.Execution) error {
+	if execution.Command == "javac" {
+		_, err := fmt.Fprintln(execution.Stdout, "javac 17.0.4")
+		return err
+	}
+	return s.Delegate.Execute(execution)
+}
+
+type FakeSBOMScanner struct{}
+
+func (FakeSBOMScanner) ScanLayer(libcnb.Layer, string, ...libcnb.SBOMFormat) error {
+	return nil
+}
+
+func (FakeSBOMScanner) ScanBuild(string, ...libcnb.SBOMFormat) error {
+	return nil
+}
+
+func (FakeSBOMScanner) ScanLaunch(string, ...libcnb.SBOMFormat) error {
+	return nil
+}
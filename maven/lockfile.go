@@ -0,0 +1,299 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+const (
+	LockfileModeDisabled = "disabled"
+	LockfileModeGenerate = "generate"
+	LockfileModeVerify   = "verify"
+)
+
+// unresolved is the access time that the LockfileExecutor resets the artifacts of the local repository to before the
+// build.  Artifacts still accessed at that time were cached by earlier builds, but not resolved by this one.
+var unresolved = time.Unix(0, 0)
+
+// Lockfile verifies the artifacts resolved by the build in the local Maven repository against a committed lock file, or
+// generates that lock file, logging it so that it can be committed.
+type Lockfile struct {
+	ApplicationPath string
+	Expected        map[string]string
+	Logger          bard.Logger
+	Mode            string
+	Path            string
+	RepositoryPath  string
+}
+
+// NewLockfile creates a new instance, reading the expected entries from the lock file at path when verifying.  path is
+// relative to the application root.
+func NewLockfile(applicationPath string, path string, mode string, repositoryPath string) (Lockfile, error) {
+	l := Lockfile{
+		ApplicationPath: applicationPath,
+		Mode:            mode,
+		Path:            path,
+		RepositoryPath:  repositoryPath,
+	}
+
+	switch mode {
+	case LockfileModeGenerate:
+	case LockfileModeVerify:
+		file := filepath.Join(applicationPath, path)
+		in, err := os.Open(file)
+		if err != nil {
+			return Lockfile{}, fmt.Errorf("unable to open lock file %s\n%w", file, err)
+		}
+		defer in.Close()
+
+		if l.Expected, err = ReadLockfileEntries(in); err != nil {
+			return Lockfile{}, fmt.Errorf("unable to read lock file %s\n%w", file, err)
+		}
+	default:
+		return Lockfile{}, fmt.Errorf("unsupported lock file mode %s, must be one of %s or %s",
+			mode, LockfileModeVerify, LockfileModeGenerate)
+	}
+
+	return l, nil
+}
+
+// Check verifies the artifacts resolved by the build against the lock file, or logs the generated lock file.
+func (l Lockfile) Check() error {
+	actual, err := NewLockfileEntries(l.RepositoryPath)
+	if err != nil {
+		return fmt.Errorf("unable to list artifacts in %s\n%w", l.RepositoryPath, err)
+	}
+
+	if l.Mode == LockfileModeGenerate {
+		// the source, and anything written beside it, is removed from the application once it has been built
+		l.Logger.Bodyf("Lock file of %d artifacts, to commit as %s:", len(actual), l.Path)
+		if err := WriteLockfileEntries(l.Logger.BodyWriter(), actual); err != nil {
+			return fmt.Errorf("unable to write lock file %s\n%w", l.Path, err)
+		}
+		return nil
+	}
+
+	l.Logger.Bodyf("Verifying %d artifacts against %s", len(actual), l.Path)
+
+	var drift []string
+	for coordinates, sha256 := range actual {
+		if expected, ok := l.Expected[coordinates]; !ok {
+			drift = append(drift, fmt.Sprintf("%s is not locked", coordinates))
+		} else if expected != sha256 {
+			drift = append(drift, fmt.Sprintf("%s has SHA-256 %s, expected %s", coordinates, sha256, expected))
+		}
+	}
+
+	for coordinates := range l.Expected {
+		if _, ok := actual[coordinates]; !ok {
+			l.Logger.Bodyf("WARNING: %s is locked but was not resolved", coordinates)
+		}
+	}
+
+	if len(drift) > 0 {
+		sort.Strings(drift)
+		return fmt.Errorf("artifacts do not match lock file %s:\n%s", l.Path, strings.Join(drift, "\n"))
+	}
+
+	return nil
+}
+
+// NewLockfileEntries returns the SHA-256 of every artifact in a local Maven repository, keyed by its
+// groupId:artifactId:version:file coordinates, except those that the LockfileExecutor marked as unresolved.
+func NewLockfileEntries(repositoryPath string) (map[string]string, error) {
+	entries := map[string]string{}
+
+	if _, err := os.Stat(repositoryPath); os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	err := filepath.Walk(repositoryPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !lockable(info.Name()) || !accessTime(info).After(unresolved) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(repositoryPath, path)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) < 4 {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open %s\n%w", path, err)
+		}
+		defer in.Close()
+
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, in); err != nil {
+			return fmt.Errorf("unable to hash %s\n%w", path, err)
+		}
+
+		n := len(parts)
+		coordinates := fmt.Sprintf("%s:%s:%s:%s", strings.Join(parts[:n-3], "."), parts[n-3], parts[n-2], parts[n-1])
+		entries[coordinates] = hex.EncodeToString(hasher.Sum(nil))
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// ReadLockfileEntries parses lock file entries of the form "<coordinates> <sha256>", ignoring blank lines and lines
+// starting with #.
+func ReadLockfileEntries(in io.Reader) (map[string]string, error) {
+	entries := map[string]string{}
+
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid lock file entry %q", line)
+		}
+		entries[fields[0]] = fields[1]
+	}
+
+	return entries, s.Err()
+}
+
+// WriteLockfileEntries writes lock file entries sorted by coordinates.
+func WriteLockfileEntries(out io.Writer, entries map[string]string) error {
+	var coordinates []string
+	for c := range entries {
+		coordinates = append(coordinates, c)
+	}
+	sort.Strings(coordinates)
+
+	sb := strings.Builder{}
+	sb.WriteString("# Generated by the Paketo Maven Buildpack. Each line is <groupId:artifactId:version:file> <sha256>.\n")
+	for _, c := range coordinates {
+		sb.WriteString(fmt.Sprintf("%s %s\n", c, entries[c]))
+	}
+
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
+
+// LockfileExecutor is an effect.Executor that checks the Lockfile once the build has succeeded.  Before the build, it
+// resets the access time of the artifacts in the local repository, so that the Lockfile only considers the artifacts
+// that the build reads or downloads rather than every artifact cached by earlier builds.  If the file system does not
+// record access times, every artifact is considered.
+type LockfileExecutor struct {
+	Delegate effect.Executor
+	Lockfile Lockfile
+}
+
+func (l LockfileExecutor) Execute(execution effect.Execution) error {
+	repository := l.Lockfile.RepositoryPath
+	if ok, err := recordsAccessTime(repository); err != nil {
+		return fmt.Errorf("unable to determine whether %s records access times\n%w", repository, err)
+	} else if !ok {
+		l.Lockfile.Logger.Bodyf("WARNING: %s does not record access times, the lock file includes every cached artifact",
+			repository)
+	} else if err := resetAccessTimes(repository); err != nil {
+		return fmt.Errorf("unable to reset access times in %s\n%w", repository, err)
+	}
+
+	if err := l.Delegate.Execute(execution); err != nil {
+		return err
+	}
+
+	return l.Lockfile.Check()
+}
+
+// recordsAccessTime returns whether reading a file in path updates its access time once it has been reset, as with the
+// relatime and strictatime mount options but not noatime.
+func recordsAccessTime(path string) (bool, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return false, fmt.Errorf("unable to create %s\n%w", path, err)
+	}
+
+	f, err := ioutil.TempFile(path, ".access-time-")
+	if err != nil {
+		return false, fmt.Errorf("unable to create file in %s\n%w", path, err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString("access-time"); err != nil {
+		f.Close()
+		return false, fmt.Errorf("unable to write %s\n%w", f.Name(), err)
+	}
+	f.Close()
+
+	if err := os.Chtimes(f.Name(), unresolved, time.Now()); err != nil {
+		return false, fmt.Errorf("unable to reset access time of %s\n%w", f.Name(), err)
+	}
+	if _, err := ioutil.ReadFile(f.Name()); err != nil {
+		return false, fmt.Errorf("unable to read %s\n%w", f.Name(), err)
+	}
+
+	info, err := os.Stat(f.Name())
+	if err != nil {
+		return false, fmt.Errorf("unable to stat %s\n%w", f.Name(), err)
+	}
+	return accessTime(info).After(unresolved), nil
+}
+
+func resetAccessTimes(path string) error {
+	return filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !lockable(info.Name()) {
+			return nil
+		}
+
+		return os.Chtimes(path, unresolved, info.ModTime())
+	})
+}
+
+func lockable(name string) bool {
+	for _, s := range []string{".jar", ".war", ".ear", ".pom"} {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
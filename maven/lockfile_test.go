@@ -0,0 +1,194 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testLockfile(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx            libcnb.BuildContext
+		repositoryPath string
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Application.Path, err = ioutil.TempDir("", "lockfile-application")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx.Layers.Path, err = ioutil.TempDir("", "lockfile-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		repositoryPath, err = ioutil.TempDir("", "lockfile-repository")
+		Expect(err).NotTo(HaveOccurred())
+
+		file := filepath.Join(repositoryPath, "org", "example", "test-artifact", "1.0.0", "test-artifact-1.0.0.jar")
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(file, []byte("test-jar"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(filepath.Dir(file), "_remote.repositories"), []byte{}, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+		Expect(os.RemoveAll(repositoryPath)).To(Succeed())
+	})
+
+	it("lists artifacts by coordinates", func() {
+		Expect(maven.NewLockfileEntries(repositoryPath)).To(Equal(map[string]string{
+			// expected: sha256 of the string "test-jar"
+			"org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar": "8d61b038e4ca10d6a60b081e0c93d173e59885a207f5f0a8a9d539751898b4d7",
+		}))
+	})
+
+	it("does not list artifacts cached by earlier builds", func() {
+		file := filepath.Join(repositoryPath, "org", "example", "old-artifact", "0.9.0", "old-artifact-0.9.0.jar")
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(file, []byte("old-jar"), 0644)).To(Succeed())
+		Expect(os.Chtimes(file, time.Unix(0, 0), time.Now())).To(Succeed())
+
+		Expect(maven.NewLockfileEntries(repositoryPath)).To(HaveKey("org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar"))
+		Expect(maven.NewLockfileEntries(repositoryPath)).NotTo(HaveKey("org.example:old-artifact:0.9.0:old-artifact-0.9.0.jar"))
+	})
+
+	it("logs the generated lock file", func() {
+		buf := &bytes.Buffer{}
+
+		l, err := maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeGenerate, repositoryPath)
+		Expect(err).NotTo(HaveOccurred())
+		l.Logger = bard.NewLogger(buf)
+
+		err = l.Check()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(ctx.Application.Path, "maven.lock")).NotTo(BeAnExistingFile())
+		Expect(buf.String()).To(ContainSubstring("Lock file of 1 artifacts, to commit as maven.lock:"))
+		Expect(buf.String()).To(ContainSubstring(
+			"org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar 8d61b038e4ca10d6a60b081e0c93d173e59885a207f5f0a8a9d539751898b4d7"))
+	})
+
+	context("LockfileExecutor", func() {
+		var (
+			buf      *bytes.Buffer
+			lockfile maven.Lockfile
+		)
+
+		it.Before(func() {
+			var err error
+
+			buf = &bytes.Buffer{}
+			lockfile, err = maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeGenerate, repositoryPath)
+			Expect(err).NotTo(HaveOccurred())
+			lockfile.Logger = bard.NewLogger(buf)
+		})
+
+		it("only considers the artifacts read by the build", func() {
+			used := filepath.Join(repositoryPath, "org", "example", "test-artifact", "1.0.0", "test-artifact-1.0.0.jar")
+			old := filepath.Join(repositoryPath, "org", "example", "old-artifact", "0.9.0", "old-artifact-0.9.0.jar")
+			Expect(os.MkdirAll(filepath.Dir(old), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(old, []byte("old-jar"), 0644)).To(Succeed())
+
+			// the build reads the artifacts it resolves
+			Expect(maven.LockfileExecutor{Delegate: ReadingExecutor{Paths: []string{used}}, Lockfile: lockfile}.
+				Execute(effect.Execution{})).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("Lock file of 1 artifacts"))
+			Expect(buf.String()).To(ContainSubstring("org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar"))
+			Expect(buf.String()).NotTo(ContainSubstring("org.example:old-artifact:0.9.0:old-artifact-0.9.0.jar"))
+		})
+
+		it("does not check the lock file when the build fails", func() {
+			delegate := &FakeExecutor{Err: fmt.Errorf("test-error")}
+
+			Expect(maven.LockfileExecutor{Delegate: delegate, Lockfile: lockfile}.Execute(effect.Execution{})).
+				To(MatchError("test-error"))
+			Expect(delegate.Executions).To(HaveLen(1))
+			Expect(buf.String()).NotTo(ContainSubstring("Lock file"))
+		})
+	})
+
+	it("verifies matching lock file", func() {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "maven.lock"), []byte(`# test lock file
+org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar 8d61b038e4ca10d6a60b081e0c93d173e59885a207f5f0a8a9d539751898b4d7
+`), 0644)).To(Succeed())
+
+		l, err := maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeVerify, repositoryPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = l.Check()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("fails when artifacts drift from lock file", func() {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "maven.lock"), []byte(`
+org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar 0000000000000000000000000000000000000000000000000000000000000000
+`), 0644)).To(Succeed())
+
+		l, err := maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeVerify, repositoryPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = l.Check()
+		Expect(err).To(MatchError(ContainSubstring("org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar has SHA-256")))
+	})
+
+	it("fails when artifacts are not locked", func() {
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "maven.lock"), []byte{}, 0644)).To(Succeed())
+
+		l, err := maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeVerify, repositoryPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = l.Check()
+		Expect(err).To(MatchError(ContainSubstring("org.example:test-artifact:1.0.0:test-artifact-1.0.0.jar is not locked")))
+	})
+
+	it("fails verification without lock file", func() {
+		_, err := maven.NewLockfile(ctx.Application.Path, "maven.lock", maven.LockfileModeVerify, repositoryPath)
+		Expect(err).To(MatchError(ContainSubstring("unable to open lock file")))
+	})
+}
+
+// ReadingExecutor is an effect.Executor that reads Paths, as a build reads the artifacts it resolves.
+type ReadingExecutor struct {
+	Paths []string
+}
+
+func (r ReadingExecutor) Execute(effect.Execution) error {
+	for _, p := range r.Paths {
+		if _, err := ioutil.ReadFile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
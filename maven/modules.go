@@ -0,0 +1,145 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// ModulesDirectory is the directory, relative to the application root, that module artifacts are staged in before
+// being restored to a subdirectory of the application root named after each module.
+const ModulesDirectory = "target/paketo-modules"
+
+// Module is an application module whose artifact is laid out under a named subdirectory of the application root.
+type Module struct {
+	Name     string
+	Path     string
+	Pattern  string
+	Artifact string
+}
+
+// ParseModules splits a comma or whitespace separated list of modules.
+func ParseModules(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// NewModules creates the modules for a list of module paths, relative to the application root.  The artifact of each
+// module is selected with defaultPattern, the name of the artifact derived from its POM breaking ties.
+func NewModules(applicationPath string, paths []string, classifier string, defaultPattern string) ([]Module, error) {
+	var modules []Module
+
+	names := map[string]string{}
+	for _, p := range paths {
+		name := filepath.Base(p)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("modules %s and %s have the same name %s", other, p, name)
+		}
+		names[name] = p
+
+		pom, err := ReadPOM(filepath.Join(applicationPath, p, "pom.xml"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read module POM\n%w", err)
+		}
+
+		module := Module{Name: name, Path: p, Pattern: filepath.Join(p, defaultPattern)}
+		if s, ok := ArtifactPattern(pom, classifier); ok && pom.ArtifactID != "" {
+			module.Artifact = filepath.Base(s)
+		}
+
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// ModulesExecutor is an effect.Executor that, once Maven has completed successfully, stages the artifact of each
+// module in ModulesDirectory.
+type ModulesExecutor struct {
+	ApplicationPath         string
+	Delegate                effect.Executor
+	InterestingFileDetector libbs.InterestingFileDetector
+	Modules                 []Module
+}
+
+func (m ModulesExecutor) Execute(execution effect.Execution) error {
+	if err := m.Delegate.Execute(execution); err != nil {
+		return err
+	}
+
+	for _, module := range m.Modules {
+		artifact, err := m.resolve(module)
+		if err != nil {
+			return err
+		}
+
+		in, err := os.Open(artifact)
+		if err != nil {
+			return fmt.Errorf("unable to open %s\n%w", artifact, err)
+		}
+
+		file := filepath.Join(m.ApplicationPath, ModulesDirectory, module.Name, filepath.Base(artifact))
+		err = sherpa.CopyFile(in, file)
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("unable to copy %s to %s\n%w", artifact, file, err)
+		}
+	}
+
+	return nil
+}
+
+func (m ModulesExecutor) resolve(module Module) (string, error) {
+	candidates, err := filepath.Glob(filepath.Join(m.ApplicationPath, module.Pattern))
+	if err != nil {
+		return "", fmt.Errorf("unable to find files with %s\n%w", module.Pattern, err)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	detector := m.InterestingFileDetector
+	if module.Artifact != "" {
+		detector = ArtifactFileDetector{Delegate: detector, Name: module.Artifact}
+	}
+
+	var artifacts []string
+	for _, c := range candidates {
+		if ok, err := detector.Interesting(c); err != nil {
+			return "", fmt.Errorf("unable to investigate %s\n%w", c, err)
+		} else if ok {
+			artifacts = append(artifacts, c)
+		}
+	}
+
+	if len(artifacts) == 1 {
+		return artifacts[0], nil
+	}
+
+	return "", fmt.Errorf("unable to find single built artifact for module %s in %s, candidates: %s",
+		module.Path, module.Pattern, candidates)
+}
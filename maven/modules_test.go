@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testModules(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "modules")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("parses comma and whitespace separated modules", func() {
+		Expect(maven.ParseModules("services/a, services/b services/c")).
+			To(Equal([]string{"services/a", "services/b", "services/c"}))
+	})
+
+	context("NewModules", func() {
+		it("selects artifacts from module POMs", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "services", "a"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "services", "a", "pom.xml"), []byte(`<project>
+  <artifactId>a</artifactId>
+  <version>1.0.0</version>
+</project>`), 0644)).To(Succeed())
+
+			Expect(maven.NewModules(path, []string{"services/a", "services/b"}, "", "target/*.[ejw]ar")).To(Equal([]maven.Module{
+				{Name: "a", Path: "services/a", Pattern: "services/a/target/*.[ejw]ar", Artifact: "a-1.0.0.jar"},
+				{Name: "b", Path: "services/b", Pattern: "services/b/target/*.[ejw]ar"},
+			}))
+		})
+
+		it("fails if modules have the same name", func() {
+			_, err := maven.NewModules(path, []string{"a/app", "b/app"}, "", "target/*.[ejw]ar")
+			Expect(err).To(MatchError("modules a/app and b/app have the same name app"))
+		})
+	})
+
+	context("ModulesExecutor", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(path, "a", "target"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "a", "target", "a.jar"), []byte("a"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(path, "b", "target"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "b", "target", "b.war"), []byte("b"), 0644)).To(Succeed())
+		})
+
+		it("stages module artifacts", func() {
+			executor := maven.ModulesExecutor{
+				ApplicationPath:         path,
+				Delegate:                &FakeExecutor{},
+				InterestingFileDetector: libbs.AlwaysInterestingFileDetector{},
+				Modules: []maven.Module{
+					{Name: "a", Path: "a", Pattern: "a/target/a.jar"},
+					{Name: "b", Path: "b", Pattern: "b/target/*.[ejw]ar"},
+				},
+			}
+
+			Expect(executor.Execute(effect.Execution{})).To(Succeed())
+			Expect(filepath.Join(path, maven.ModulesDirectory, "a", "a.jar")).To(BeARegularFile())
+			Expect(filepath.Join(path, maven.ModulesDirectory, "b", "b.war")).To(BeARegularFile())
+		})
+
+		it("fails if a module artifact is ambiguous", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "b", "target", "b-plain.war"), []byte("b"), 0644)).To(Succeed())
+
+			executor := maven.ModulesExecutor{
+				ApplicationPath:         path,
+				Delegate:                &FakeExecutor{},
+				InterestingFileDetector: libbs.AlwaysInterestingFileDetector{},
+				Modules:                 []maven.Module{{Name: "b", Path: "b", Pattern: "b/target/*.[ejw]ar"}},
+			}
+
+			Expect(executor.Execute(effect.Execution{})).To(MatchError(ContainSubstring(
+				"unable to find single built artifact for module b in b/target/*.[ejw]ar")))
+		})
+
+		it("breaks the tie with the artifact derived from the module POM", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "b", "target", "b-plain.war"), []byte("b"), 0644)).To(Succeed())
+
+			executor := maven.ModulesExecutor{
+				ApplicationPath:         path,
+				Delegate:                &FakeExecutor{},
+				InterestingFileDetector: libbs.AlwaysInterestingFileDetector{},
+				Modules:                 []maven.Module{{Name: "b", Path: "b", Pattern: "b/target/*.[ejw]ar", Artifact: "b.war"}},
+			}
+
+			Expect(executor.Execute(effect.Execution{})).To(Succeed())
+			Expect(filepath.Join(path, maven.ModulesDirectory, "b", "b.war")).To(BeARegularFile())
+			Expect(filepath.Join(path, maven.ModulesDirectory, "b", "b-plain.war")).NotTo(BeAnExistingFile())
+		})
+	})
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+const (
+	// MvndClientJVM selects the JVM client of the Maven Daemon, which only requires a POSIX shell and a JVM.
+	MvndClientJVM = "jvm"
+
+	// MvndClientNative selects the native client of the Maven Daemon, which requires glibc.
+	MvndClientNative = "native"
+
+	// MvndIdleTimeout is the idle timeout of the daemon, a safety net should it not be stopped after the build.
+	MvndIdleTimeout = "5m"
+
+	// MvndMinHeapSize is the default minimum heap size of the daemon, in megabytes.
+	MvndMinHeapSize = 128
+)
+
+// MvndDaemon contributes an empty layer used as the storage of the Maven Daemon's registry and logs for the duration
+// of the build.
+type MvndDaemon struct {
+	Logger bard.Logger
+}
+
+func (d MvndDaemon) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.RemoveAll(layer.Path); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", layer.Path, err)
+	}
+
+	d.Logger.Bodyf("Creating daemon storage %s", layer.Path)
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer directory %s\n%w", layer.Path, err)
+	}
+
+	return layer, nil
+}
+
+func (MvndDaemon) Name() string {
+	return "mvnd-daemon"
+}
+
+// MvndArguments returns the -Dmvnd.* options for a daemon storing its state in storagePath, with heap sizes derived
+// from the memory limit of the cgroup mounted at cgroupPath.
+func MvndArguments(storagePath string, cgroupPath string) []string {
+	args := []string{
+		fmt.Sprintf("-Dmvnd.daemonStorage=%s", storagePath),
+		fmt.Sprintf("-Dmvnd.idleTimeout=%s", MvndIdleTimeout),
+	}
+
+	if limit, ok := CgroupMemoryLimit(cgroupPath); ok {
+		max := limit * 3 / 4 / 1024 / 1024
+		min := int64(MvndMinHeapSize)
+		if max < min {
+			min = max
+		}
+
+		args = append(args,
+			fmt.Sprintf("-Dmvnd.minHeapSize=%dm", min),
+			fmt.Sprintf("-Dmvnd.maxHeapSize=%dm", max),
+		)
+	}
+
+	return args
+}
+
+// MvndJVMArguments returns args with jvmArgs added to the options of the daemon's JVM, appending them to an existing
+// -Dmvnd.jvmArgs option.
+func MvndJVMArguments(args []string, jvmArgs string) []string {
+	args = append([]string{}, args...)
+	for i := len(args) - 1; i >= 0; i-- {
+		if strings.HasPrefix(args[i], "-Dmvnd.jvmArgs=") {
+			args[i] = fmt.Sprintf("%s %s", args[i], jvmArgs)
+			return args
+		}
+	}
+	return append(args, fmt.Sprintf("-Dmvnd.jvmArgs=%s", jvmArgs))
+}
+
+// CgroupMemoryLimit returns the memory limit, in bytes, of the cgroup (v2 or v1) mounted at path.  Returns false if
+// the memory is not limited.
+func CgroupMemoryLimit(path string) (int64, bool) {
+	for _, f := range []string{
+		filepath.Join(path, "memory.max"),
+		filepath.Join(path, "memory", "memory.limit_in_bytes"),
+	} {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		limit, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil || limit <= 0 || limit >= 1<<62 {
+			// "max" in v2, or a value approaching the maximum int64 in v1, means unlimited
+			return 0, false
+		}
+
+		return limit, true
+	}
+
+	return 0, false
+}
+
+// MvndExecutor is an effect.Executor that runs the Maven Daemon with its home, storage and heap configured, and stops
+// the daemon once the build has completed so that no processes are leaked.  The daemon is stopped by Stopper rather
+// than Delegate, so that the stop command is neither timed nor retried as a build.
+type MvndExecutor struct {
+	Arguments []string
+	Delegate  effect.Executor
+	Home      string
+	Logger    bard.Logger
+	Stopper   effect.Executor
+}
+
+func (m MvndExecutor) Execute(execution effect.Execution) error {
+	if filepath.Ext(execution.Command) != ".sh" {
+		if err := NativeClientRunnable(execution.Command); err != nil {
+			m.Logger.Bodyf("WARNING: falling back to the JVM client of the Maven Daemon:\n%s", err)
+			execution.Command = fmt.Sprintf("%s.sh", execution.Command)
+		}
+	}
+
+	execution.Args = append(append([]string{}, m.Arguments...), execution.Args...)
+	execution.Env = append(environ(execution), fmt.Sprintf("MVND_HOME=%s", m.Home))
+
+	err := m.Delegate.Execute(execution)
+
+	m.Logger.Body("Stopping Maven Daemon")
+	if stopErr := m.Stopper.Execute(effect.Execution{
+		Command: execution.Command,
+		Args:    append([]string{"--stop"}, m.Arguments...),
+		Dir:     execution.Dir,
+		Env:     execution.Env,
+		Stdout:  execution.Stdout,
+		Stderr:  execution.Stderr,
+	}); stopErr != nil {
+		m.Logger.Bodyf("WARNING: unable to stop Maven Daemon:\n%s", stopErr)
+	}
+
+	return err
+}
+
+// NativeClientRunnable returns an error if the native client at path cannot run on the current stack, typically because
+// the dynamic linker it requires, part of glibc, is missing.
+func NativeClientRunnable(path string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to read native client %s\n%w", path, err)
+	}
+	defer f.Close()
+
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_INTERP {
+			continue
+		}
+
+		b := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(b, 0); err != nil {
+			return fmt.Errorf("unable to read interpreter of %s\n%w", path, err)
+		}
+
+		interpreter := string(bytes.TrimRight(b, "\x00"))
+		if _, err := os.Stat(interpreter); err != nil {
+			return fmt.Errorf("interpreter %s of %s is not available\n%w", interpreter, path, err)
+		}
+	}
+
+	return nil
+}
+
+// environ returns the environment of an execution, defaulting to the current environment.
+func environ(execution effect.Execution) []string {
+	if len(execution.Env) > 0 {
+		return append([]string{}, execution.Env...)
+	}
+	return os.Environ()
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testMvndDaemon(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "mvnd-daemon")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("contributes an empty daemon storage", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "storage", "registry"), 0755)).To(Succeed())
+
+		layer, err := maven.MvndDaemon{}.Contribute(libcnb.Layer{Path: filepath.Join(path, "storage")})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Path).To(BeADirectory())
+		Expect(filepath.Join(layer.Path, "registry")).NotTo(BeAnExistingFile())
+	})
+
+	context("MvndArguments", func() {
+		it("does not size the heap without a memory limit", func() {
+			Expect(maven.MvndArguments("test-storage", path)).To(Equal([]string{
+				"-Dmvnd.daemonStorage=test-storage",
+				"-Dmvnd.idleTimeout=5m",
+			}))
+		})
+
+		it("does not size the heap with an unlimited cgroup v2", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "memory.max"), []byte("max\n"), 0644)).To(Succeed())
+
+			Expect(maven.MvndArguments("test-storage", path)).To(HaveLen(2))
+		})
+
+		it("sizes the heap from a cgroup v2 limit", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "memory.max"), []byte(fmt.Sprintf("%d\n", 2048*1024*1024)), 0644)).
+				To(Succeed())
+
+			Expect(maven.MvndArguments("test-storage", path)).To(Equal([]string{
+				"-Dmvnd.daemonStorage=test-storage",
+				"-Dmvnd.idleTimeout=5m",
+				"-Dmvnd.minHeapSize=128m",
+				"-Dmvnd.maxHeapSize=1536m",
+			}))
+		})
+
+		it("sizes the heap from a cgroup v1 limit", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "memory"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "memory", "memory.limit_in_bytes"), []byte(fmt.Sprintf("%d\n", 128*1024*1024)), 0644)).
+				To(Succeed())
+
+			Expect(maven.MvndArguments("test-storage", path)).To(ContainElements(
+				"-Dmvnd.minHeapSize=96m",
+				"-Dmvnd.maxHeapSize=96m",
+			))
+		})
+
+		it("does not size the heap with an unlimited cgroup v1", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "memory"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "memory", "memory.limit_in_bytes"), []byte("9223372036854771712\n"), 0644)).
+				To(Succeed())
+
+			Expect(maven.MvndArguments("test-storage", path)).To(HaveLen(2))
+		})
+	})
+
+	context("MvndJVMArguments", func() {
+		it("adds the options of the daemon's JVM", func() {
+			Expect(maven.MvndJVMArguments([]string{"-Dmvnd.idleTimeout=5m"}, "-Dtest-key=test-value")).To(Equal([]string{
+				"-Dmvnd.idleTimeout=5m",
+				"-Dmvnd.jvmArgs=-Dtest-key=test-value",
+			}))
+		})
+
+		it("appends to existing options of the daemon's JVM", func() {
+			args := []string{"-Dmvnd.jvmArgs=-Xss1m", "-Dmvnd.threads=2"}
+
+			Expect(maven.MvndJVMArguments(args, "-Dtest-key=test-value")).To(Equal([]string{
+				"-Dmvnd.jvmArgs=-Xss1m -Dtest-key=test-value",
+				"-Dmvnd.threads=2",
+			}))
+			Expect(args[0]).To(Equal("-Dmvnd.jvmArgs=-Xss1m"))
+		})
+	})
+
+	context("NativeClientRunnable", func() {
+		it("accepts a client whose interpreter exists", func() {
+			Expect(writeELF(filepath.Join(path, "mvnd"), filepath.Join(path, "ld.so"))).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "ld.so"), []byte{}, 0755)).To(Succeed())
+
+			Expect(maven.NativeClientRunnable(filepath.Join(path, "mvnd"))).To(Succeed())
+		})
+
+		it("rejects a client whose interpreter is missing", func() {
+			Expect(writeELF(filepath.Join(path, "mvnd"), filepath.Join(path, "ld.so"))).To(Succeed())
+
+			Expect(maven.NativeClientRunnable(filepath.Join(path, "mvnd"))).To(MatchError(ContainSubstring(
+				fmt.Sprintf("interpreter %s of %s is not available", filepath.Join(path, "ld.so"), filepath.Join(path, "mvnd")))))
+		})
+
+		it("rejects a client that is not an executable", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "mvnd"), []byte("test-script"), 0755)).To(Succeed())
+
+			Expect(maven.NativeClientRunnable(filepath.Join(path, "mvnd"))).To(HaveOccurred())
+		})
+	})
+
+	context("MvndExecutor", func() {
+		var (
+			delegate *FakeExecutor
+			executor maven.MvndExecutor
+			stopper  *FakeExecutor
+		)
+
+		it.Before(func() {
+			delegate = &FakeExecutor{}
+			stopper = &FakeExecutor{}
+			executor = maven.MvndExecutor{
+				Arguments: []string{"-Dmvnd.daemonStorage=test-storage"},
+				Delegate:  delegate,
+				Home:      "test-home",
+				Logger:    bard.NewLogger(ioutil.Discard),
+				Stopper:   stopper,
+			}
+		})
+
+		it("runs the build and stops the daemon", func() {
+			Expect(executor.Execute(effect.Execution{
+				Command: "mvnd.sh",
+				Args:    []string{"package"},
+				Dir:     path,
+				Env:     []string{"TEST_KEY=test-value"},
+			})).To(Succeed())
+
+			Expect(delegate.Executions).To(HaveLen(1))
+			Expect(delegate.Executions[0].Args).To(Equal([]string{"-Dmvnd.daemonStorage=test-storage", "package"}))
+			Expect(delegate.Executions[0].Env).To(Equal([]string{"TEST_KEY=test-value", "MVND_HOME=test-home"}))
+			Expect(stopper.Executions).To(HaveLen(1))
+			Expect(stopper.Executions[0].Command).To(Equal("mvnd.sh"))
+			Expect(stopper.Executions[0].Args).To(Equal([]string{"--stop", "-Dmvnd.daemonStorage=test-storage"}))
+			Expect(stopper.Executions[0].Dir).To(Equal(path))
+			Expect(stopper.Executions[0].Env).To(Equal([]string{"TEST_KEY=test-value", "MVND_HOME=test-home"}))
+		})
+
+		it("falls back to the JVM client", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "mvnd"), []byte("test-script"), 0755)).To(Succeed())
+
+			Expect(executor.Execute(effect.Execution{Command: filepath.Join(path, "mvnd")})).To(Succeed())
+			Expect(delegate.Executions[0].Command).To(Equal(filepath.Join(path, "mvnd.sh")))
+			Expect(stopper.Executions[0].Command).To(Equal(filepath.Join(path, "mvnd.sh")))
+		})
+
+		it("does not check the JVM client", func() {
+			Expect(executor.Execute(effect.Execution{Command: filepath.Join(path, "mvnd.sh")})).To(Succeed())
+			Expect(delegate.Executions[0].Command).To(Equal(filepath.Join(path, "mvnd.sh")))
+		})
+
+		it("stops the daemon when the build fails", func() {
+			delegate.Err = fmt.Errorf("test-error")
+
+			Expect(executor.Execute(effect.Execution{Command: "mvnd.sh"})).To(MatchError("test-error"))
+			Expect(stopper.Executions).To(HaveLen(1))
+			Expect(stopper.Executions[0].Args[0]).To(Equal("--stop"))
+		})
+
+		it("returns the result of the build if the daemon cannot be stopped", func() {
+			stopper.Err = fmt.Errorf("test-error")
+
+			Expect(executor.Execute(effect.Execution{Command: "mvnd.sh"})).To(Succeed())
+			Expect(delegate.Executions).To(HaveLen(1))
+		})
+	})
+}
+
+// writeELF writes a minimal 64-bit ELF executable requiring interpreter.
+func writeELF(path string, interpreter string) error {
+	const headerSize, programHeaderSize = 64, 56
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	interp := append([]byte(interpreter), 0)
+
+	header := struct {
+		Ident     [16]byte
+		Type      uint16
+		Machine   uint16
+		Version   uint32
+		Entry     uint64
+		Phoff     uint64
+		Shoff     uint64
+		Flags     uint32
+		Ehsize    uint16
+		Phentsize uint16
+		Phnum     uint16
+		Shentsize uint16
+		Shnum     uint16
+		Shstrndx  uint16
+	}{
+		Ident:     [16]byte{0x7f, 'E', 'L', 'F', 2, 1, 1},
+		Type:      2,
+		Machine:   62,
+		Version:   1,
+		Phoff:     headerSize,
+		Ehsize:    headerSize,
+		Phentsize: programHeaderSize,
+		Phnum:     1,
+	}
+
+	program := struct {
+		Type   uint32
+		Flags  uint32
+		Off    uint64
+		Vaddr  uint64
+		Paddr  uint64
+		Filesz uint64
+		Memsz  uint64
+		Align  uint64
+	}{
+		Type:   3,
+		Flags:  4,
+		Off:    headerSize + programHeaderSize,
+		Filesz: uint64(len(interp)),
+		Memsz:  uint64(len(interp)),
+		Align:  1,
+	}
+
+	if err := binary.Write(f, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if err := binary.Write(f, binary.LittleEndian, program); err != nil {
+		return err
+	}
+	_, err = f.Write(interp)
+	return err
+}
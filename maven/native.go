@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+const (
+	PlanEntryNativeImageBuilder = "native-image-builder"
+
+	// NativeProfile is the profile that the native-maven-plugin documents for building native images.
+	NativeProfile = "native"
+)
+
+// NativeDirectory is the directory, relative to the application root, that the native image is staged in before being
+// restored to the application root.
+const NativeDirectory = "target/paketo-native"
+
+// NativeImage returns the path, relative to the project, of the executable built by the native-maven-plugin in the
+// native profile of a POM.  Returns false if the POM has no such profile.
+func NativeImage(pom POM) (string, bool) {
+	for _, profile := range pom.Profiles {
+		if profile.ID != NativeProfile {
+			continue
+		}
+
+		for _, plugin := range profile.Plugins {
+			if plugin.ArtifactID != "native-maven-plugin" {
+				continue
+			}
+
+			name := plugin.Configuration.ImageName
+			for _, e := range plugin.Executions {
+				if name == "" {
+					name = e.Configuration.ImageName
+				}
+			}
+			if name == "" {
+				name = "${project.artifactId}"
+			}
+			name = pom.Interpolate(name)
+
+			if strings.Contains(name, "${") {
+				name = pom.ArtifactID
+			}
+
+			return filepath.Join("target", name), true
+		}
+	}
+
+	return "", false
+}
+
+// NativeExecutor is an effect.Executor that, once Maven has completed successfully, stages the native image in an
+// archive that is restored to the application root, preserving its permissions.
+type NativeExecutor struct {
+	ApplicationPath string
+	Archive         string
+	Delegate        effect.Executor
+	Executable      string
+}
+
+func (n NativeExecutor) Execute(execution effect.Execution) error {
+	if err := n.Delegate.Execute(execution); err != nil {
+		return err
+	}
+
+	executable := filepath.Join(n.ApplicationPath, n.Executable)
+	in, err := os.Open(executable)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("unable to find native image %s, ensure the %s profile builds it with native:compile",
+			n.Executable, NativeProfile)
+	} else if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", executable, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat %s\n%w", executable, err)
+	}
+
+	file := filepath.Join(n.ApplicationPath, n.Archive)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to create %s\n%w", file, err)
+	}
+	defer out.Close()
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("unable to create header for %s\n%w", executable, err)
+	}
+	header.Method = zip.Deflate
+
+	z := zip.NewWriter(out)
+	w, err := z.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("unable to write %s to %s\n%w", header.Name, file, err)
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("unable to write %s to %s\n%w", header.Name, file, err)
+	}
+
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("unable to close %s\n%w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/crush"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testNative(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "native")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	context("NativeImage", func() {
+		it("does not find an image without a native profile", func() {
+			_, ok := maven.NativeImage(maven.POM{ArtifactID: "test-artifact"})
+			Expect(ok).To(BeFalse())
+		})
+
+		it("does not find an image in a native profile without the native-maven-plugin", func() {
+			_, ok := maven.NativeImage(maven.POM{
+				ArtifactID: "test-artifact",
+				Profiles:   []maven.Profile{{ID: "native"}},
+			})
+			Expect(ok).To(BeFalse())
+		})
+
+		it("names the image after the artifactId", func() {
+			image, ok := maven.NativeImage(maven.POM{
+				ArtifactID: "test-artifact",
+				Profiles: []maven.Profile{{
+					ID:      "native",
+					Plugins: []maven.Plugin{{ArtifactID: "native-maven-plugin"}},
+				}},
+			})
+			Expect(ok).To(BeTrue())
+			Expect(image).To(Equal("target/test-artifact"))
+		})
+
+		it("names the image after the imageName", func() {
+			image, ok := maven.NativeImage(maven.POM{
+				ArtifactID: "test-artifact",
+				Profiles: []maven.Profile{{
+					ID: "native",
+					Plugins: []maven.Plugin{{
+						ArtifactID:    "native-maven-plugin",
+						Configuration: maven.Configuration{ImageName: "${project.artifactId}-app"},
+					}},
+				}},
+			})
+			Expect(ok).To(BeTrue())
+			Expect(image).To(Equal("target/test-artifact-app"))
+		})
+	})
+
+	context("NativeExecutor", func() {
+		it("stages the native image", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "target"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "target", "app"), []byte("test-image"), 0755)).To(Succeed())
+
+			executor := maven.NativeExecutor{
+				ApplicationPath: path,
+				Archive:         "target/paketo-native/app.zip",
+				Delegate:        &FakeExecutor{},
+				Executable:      "target/app",
+			}
+			Expect(executor.Execute(effect.Execution{})).To(Succeed())
+
+			in, err := os.Open(filepath.Join(path, "target", "paketo-native", "app.zip"))
+			Expect(err).NotTo(HaveOccurred())
+			defer in.Close()
+
+			out := filepath.Join(path, "out")
+			Expect(crush.ExtractZip(in, out, 0)).To(Succeed())
+			Expect(ioutil.ReadFile(filepath.Join(out, "app"))).To(Equal([]byte("test-image")))
+
+			info, err := os.Stat(filepath.Join(out, "app"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm() & 0100).NotTo(BeZero())
+		})
+
+		it("fails if the native image was not built", func() {
+			executor := maven.NativeExecutor{
+				ApplicationPath: path,
+				Archive:         "target/paketo-native/app.zip",
+				Delegate:        &FakeExecutor{},
+				Executable:      "target/app",
+			}
+			Expect(executor.Execute(effect.Execution{})).To(MatchError(
+				"unable to find native image target/app, ensure the native profile builds it with native:compile"))
+		})
+	})
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+const (
+	PlanEntryNode = "node"
+	PlanEntryYarn = "yarn"
+)
+
+// basedir replaces the project directories that the frontend-maven-plugin install directory is commonly configured
+// relative to.
+var basedir = strings.NewReplacer(
+	"${project.basedir}", ".",
+	"${basedir}", ".",
+	"${project.build.directory}", "target",
+)
+
+// Node describes the use of Node.js by the projects of a reactor.
+type Node struct {
+	// InstallDirectories are the directories, relative to the application root, that the frontend-maven-plugin installs
+	// Node.js into.
+	InstallDirectories []string
+
+	// Node is whether Node.js is used.
+	Node bool
+
+	// Yarn is whether Yarn is used.
+	Yarn bool
+}
+
+// NewNode inspects the reactor rooted at pomFile for projects running Node.js with the frontend-maven-plugin or the
+// exec-maven-plugin.
+func NewNode(applicationPath string, pomFile string) (Node, error) {
+	var n Node
+
+	if _, err := walkReactor(applicationPath, pomFile, func(dir string, pom POM) {
+		if plugin, ok := pom.Plugin("frontend-maven-plugin"); ok {
+			n.Node = true
+
+			install := pom.Interpolate(basedir.Replace(plugin.Configuration.InstallDirectory))
+			if !filepath.IsAbs(install) {
+				install = filepath.Join(dir, install)
+			}
+			n.InstallDirectories = append(n.InstallDirectories, install)
+
+			for _, e := range plugin.Executions {
+				for _, g := range e.Goals {
+					if strings.Contains(g, "yarn") {
+						n.Yarn = true
+					}
+				}
+			}
+		}
+
+		if plugin, ok := pom.Plugin("exec-maven-plugin"); ok {
+			executables := []string{plugin.Configuration.Executable}
+			for _, e := range plugin.Executions {
+				executables = append(executables, e.Configuration.Executable)
+			}
+
+			for _, e := range executables {
+				switch filepath.Base(e) {
+				case "node", "npm", "npx":
+					n.Node = true
+				case "yarn":
+					n.Node, n.Yarn = true, true
+				}
+			}
+		}
+	}); err != nil {
+		return Node{}, err
+	}
+
+	return n, nil
+}
+
+// NodeExecutor is an effect.Executor that, before running Maven, links the Node.js tools found on $PATH into the
+// directories the frontend-maven-plugin runs them from.
+type NodeExecutor struct {
+	ApplicationPath    string
+	Delegate           effect.Executor
+	InstallDirectories []string
+	Logger             bard.Logger
+	Yarn               bool
+}
+
+func (n NodeExecutor) Execute(execution effect.Execution) error {
+	links := map[string]string{}
+
+	node, err := exec.LookPath("node")
+	if err != nil {
+		return fmt.Errorf("unable to find node on $PATH\n%w", err)
+	}
+	links["node"] = node
+
+	if npm, ok, err := packageDirectory("npm"); err != nil {
+		return err
+	} else if ok {
+		links[filepath.Join("node_modules", "npm")] = npm
+	}
+
+	if n.Yarn {
+		if yarn, ok, err := packageDirectory("yarn"); err != nil {
+			return err
+		} else if ok {
+			links[filepath.Join("yarn", "dist")] = yarn
+		}
+	}
+
+	for _, d := range n.InstallDirectories {
+		dir := filepath.Join(n.ApplicationPath, d, "node")
+		n.Logger.Bodyf("Linking Node.js to %s", dir)
+
+		for name, target := range links {
+			file := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+			}
+
+			if err := os.RemoveAll(file); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", file, err)
+			}
+
+			if err := os.Symlink(target, file); err != nil {
+				return fmt.Errorf("unable to link %s to %s\n%w", file, target, err)
+			}
+		}
+	}
+
+	return n.Delegate.Execute(execution)
+}
+
+// packageDirectory returns the directory of the package whose bin/ script command on $PATH resolves to.
+func packageDirectory(command string) (string, bool, error) {
+	file, err := exec.LookPath(command)
+	if err != nil {
+		return "", false, nil
+	}
+
+	file, err = filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to resolve %s\n%w", command, err)
+	}
+
+	return filepath.Dir(filepath.Dir(file)), true, nil
+}
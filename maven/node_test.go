@@ -0,0 +1,182 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testNode(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	writeFile := func(file string, content string, mode os.FileMode) {
+		Expect(os.MkdirAll(filepath.Dir(filepath.Join(path, file)), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, file), []byte(content), mode)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "node")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	context("NewNode", func() {
+		it("does not use Node.js without plugins", func() {
+			writeFile("pom.xml", `<project><artifactId>test-artifact</artifactId></project>`, 0644)
+
+			Expect(maven.NewNode(path, "pom.xml")).To(Equal(maven.Node{}))
+		})
+
+		it("finds the frontend-maven-plugin in modules", func() {
+			writeFile("pom.xml", `<project>
+  <modules>
+    <module>frontend</module>
+    <module>admin</module>
+  </modules>
+</project>`, 0644)
+			writeFile("frontend/pom.xml", `<project>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>com.github.eirslett</groupId>
+        <artifactId>frontend-maven-plugin</artifactId>
+        <executions>
+          <execution>
+            <goals><goal>install-node-and-npm</goal></goals>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`, 0644)
+			writeFile("admin/pom.xml", `<project>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>com.github.eirslett</groupId>
+        <artifactId>frontend-maven-plugin</artifactId>
+        <configuration>
+          <installDirectory>${project.build.directory}</installDirectory>
+        </configuration>
+        <executions>
+          <execution>
+            <goals><goal>install-node-and-yarn</goal></goals>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`, 0644)
+
+			Expect(maven.NewNode(path, "pom.xml")).To(Equal(maven.Node{
+				InstallDirectories: []string{"frontend", "admin/target"},
+				Node:               true,
+				Yarn:               true,
+			}))
+		})
+
+		it("finds the exec-maven-plugin running npm", func() {
+			writeFile("pom.xml", `<project>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>org.codehaus.mojo</groupId>
+        <artifactId>exec-maven-plugin</artifactId>
+        <executions>
+          <execution>
+            <goals><goal>exec</goal></goals>
+            <configuration>
+              <executable>npm</executable>
+            </configuration>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`, 0644)
+
+			Expect(maven.NewNode(path, "pom.xml")).To(Equal(maven.Node{Node: true}))
+		})
+	})
+
+	context("NodeExecutor", func() {
+		var previous string
+
+		it.Before(func() {
+			previous = os.Getenv("PATH")
+
+			writeFile("layers/node/bin/node", "", 0755)
+			writeFile("layers/node/lib/node_modules/npm/bin/npm-cli.js", "", 0755)
+			Expect(os.Symlink(filepath.Join(path, "layers", "node", "lib", "node_modules", "npm", "bin", "npm-cli.js"),
+				filepath.Join(path, "layers", "node", "bin", "npm"))).To(Succeed())
+			writeFile("layers/yarn/bin/yarn.js", "", 0755)
+			Expect(os.MkdirAll(filepath.Join(path, "layers", "yarn", "shims"), 0755)).To(Succeed())
+			Expect(os.Symlink(filepath.Join(path, "layers", "yarn", "bin", "yarn.js"),
+				filepath.Join(path, "layers", "yarn", "shims", "yarn"))).To(Succeed())
+
+			Expect(os.Setenv("PATH", filepath.Join(path, "layers", "node", "bin")+string(os.PathListSeparator)+
+				filepath.Join(path, "layers", "yarn", "shims"))).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Setenv("PATH", previous)).To(Succeed())
+		})
+
+		it("links Node.js into the install directories", func() {
+			delegate := &FakeExecutor{}
+
+			Expect(maven.NodeExecutor{
+				ApplicationPath:    filepath.Join(path, "workspace"),
+				Delegate:           delegate,
+				InstallDirectories: []string{"frontend"},
+				Yarn:               true,
+			}.Execute(effect.Execution{})).To(Succeed())
+
+			dir := filepath.Join(path, "workspace", "frontend", "node")
+			Expect(os.Readlink(filepath.Join(dir, "node"))).To(Equal(filepath.Join(path, "layers", "node", "bin", "node")))
+			Expect(os.Readlink(filepath.Join(dir, "node_modules", "npm"))).
+				To(Equal(filepath.Join(path, "layers", "node", "lib", "node_modules", "npm")))
+			Expect(os.Readlink(filepath.Join(dir, "yarn", "dist"))).To(Equal(filepath.Join(path, "layers", "yarn")))
+			Expect(delegate.Executions).To(HaveLen(1))
+		})
+
+		it("fails without Node.js", func() {
+			Expect(os.Setenv("PATH", filepath.Join(path, "layers", "yarn", "shims"))).To(Succeed())
+
+			Expect(maven.NodeExecutor{Delegate: &FakeExecutor{}}.Execute(effect.Execution{})).
+				To(MatchError(ContainSubstring("unable to find node on $PATH")))
+		})
+	})
+}
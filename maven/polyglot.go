@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PolyglotGroupID is the group id of the Polyglot Maven core extensions.
+const PolyglotGroupID = "io.takari.polyglot"
+
+// PolyglotPOMs are the POM files, in order of precedence, read by the Polyglot Maven extensions.
+var PolyglotPOMs = []string{
+	"pom.yaml",
+	"pom.yml",
+	"pom.kts",
+	"pom.groovy",
+	"pom.rb",
+	"pom.scala",
+	"pom.clj",
+	"pom.atom",
+	"pom.java",
+}
+
+// Extensions are the core extensions declared in .mvn/extensions.xml.
+type Extensions struct {
+	Extensions []Extension `xml:"extension"`
+}
+
+// Extension is a core extension.
+type Extension struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// ReadExtensions reads the core extensions of the project at applicationPath.  Returns no extensions if the project
+// does not declare any.
+func ReadExtensions(applicationPath string) (Extensions, error) {
+	file := filepath.Join(applicationPath, ".mvn", "extensions.xml")
+
+	in, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return Extensions{}, nil
+	} else if err != nil {
+		return Extensions{}, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	var e Extensions
+	if err := xml.NewDecoder(in).Decode(&e); err != nil && !errors.Is(err, io.EOF) {
+		return Extensions{}, fmt.Errorf("unable to decode %s\n%w", file, err)
+	}
+
+	return e, nil
+}
+
+// PolyglotPOM returns the Polyglot Maven POM file of the project at applicationPath, relative to applicationPath.
+// Returns false if the project does not declare a Polyglot Maven extension or has no polyglot POM.
+func PolyglotPOM(applicationPath string) (string, bool, error) {
+	e, err := ReadExtensions(applicationPath)
+	if err != nil {
+		return "", false, err
+	}
+
+	polyglot := false
+	for _, x := range e.Extensions {
+		if x.GroupID == PolyglotGroupID && strings.HasPrefix(x.ArtifactID, "polyglot-") {
+			polyglot = true
+			break
+		}
+	}
+
+	if !polyglot {
+		return "", false, nil
+	}
+
+	for _, p := range PolyglotPOMs {
+		if _, err := os.Stat(filepath.Join(applicationPath, p)); err == nil {
+			return p, true, nil
+		} else if !os.IsNotExist(err) {
+			return "", false, fmt.Errorf("unable to determine if %s exists\n%w", p, err)
+		}
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testPolyglot(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	writeExtensions := func(groupID string, artifactID string) {
+		Expect(os.MkdirAll(filepath.Join(path, ".mvn"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, ".mvn", "extensions.xml"), []byte(`<extensions>
+  <extension>
+    <groupId>`+groupID+`</groupId>
+    <artifactId>`+artifactID+`</artifactId>
+    <version>0.4.8</version>
+  </extension>
+</extensions>`), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "polyglot")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("reads no extensions without .mvn/extensions.xml", func() {
+		Expect(maven.ReadExtensions(path)).To(Equal(maven.Extensions{}))
+	})
+
+	it("returns the polyglot POM", func() {
+		writeExtensions("io.takari.polyglot", "polyglot-yaml")
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.yml"), []byte{}, 0644)).To(Succeed())
+
+		pom, ok, err := maven.PolyglotPOM(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(pom).To(Equal("pom.yml"))
+	})
+
+	it("ignores polyglot POMs without a polyglot extension", func() {
+		writeExtensions("test-group", "test-artifact")
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.kts"), []byte{}, 0644)).To(Succeed())
+
+		_, ok, err := maven.PolyglotPOM(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	it("ignores a polyglot extension without a polyglot POM", func() {
+		writeExtensions("io.takari.polyglot", "polyglot-kotlin")
+
+		_, ok, err := maven.PolyglotPOM(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// POM is the subset of a Maven project object model that the buildpack inspects.
+type POM struct {
+	ModelVersion string     `xml:"modelVersion"`
+	Parent       Parent     `xml:"parent"`
+	GroupID      string     `xml:"groupId"`
+	ArtifactID   string     `xml:"artifactId"`
+	Version      string     `xml:"version"`
+	Packaging    string     `xml:"packaging"`
+	Properties   Properties `xml:"properties"`
+	Modules      []string   `xml:"modules>module"`
+	FinalName    string     `xml:"build>finalName"`
+	Plugins      []Plugin   `xml:"build>plugins>plugin"`
+	Profiles     []Profile  `xml:"profiles>profile"`
+
+	DependencyManagement []Dependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+// Dependency is a dependency declared by a POM.
+type Dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Scope      string `xml:"scope"`
+}
+
+// Profile is a profile declared by a POM.
+type Profile struct {
+	ID      string   `xml:"id"`
+	Plugins []Plugin `xml:"build>plugins>plugin"`
+}
+
+// Parent is the parent declared by a POM.
+type Parent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// Plugin is a plugin declared in the build section of a POM.
+type Plugin struct {
+	GroupID       string        `xml:"groupId"`
+	ArtifactID    string        `xml:"artifactId"`
+	Version       string        `xml:"version"`
+	Configuration Configuration `xml:"configuration"`
+	Executions    []Execution   `xml:"executions>execution"`
+}
+
+// Execution is an execution of a plugin.
+type Execution struct {
+	ID            string        `xml:"id"`
+	Goals         []string      `xml:"goals>goal"`
+	Configuration Configuration `xml:"configuration"`
+}
+
+// Configuration is the subset of plugin configuration that the buildpack inspects.
+type Configuration struct {
+	Classifier       string `xml:"classifier"`
+	Executable       string `xml:"executable"`
+	ImageName        string `xml:"imageName"`
+	InstallDirectory string `xml:"installDirectory"`
+	MainClass        string `xml:"transformers>transformer>mainClass"`
+}
+
+// Properties are the properties declared in a POM.
+type Properties map[string]string
+
+func (p *Properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = Properties{}
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch e := t.(type) {
+		case xml.StartElement:
+			var v string
+			if err := d.DecodeElement(&v, &e); err != nil {
+				return err
+			}
+			(*p)[e.Name.Local] = v
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// ReadPOM reads the POM at path, in the encoding declared by its XML declaration. An empty POM is returned if the file
+// does not exist or is empty.
+func ReadPOM(path string) (POM, error) {
+	var pom POM
+
+	in, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return pom, nil
+	} else if err != nil {
+		return POM{}, fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer in.Close()
+
+	d := xml.NewDecoder(in)
+	d.CharsetReader = charset.NewReaderLabel
+	if err := d.Decode(&pom); err != nil && !errors.Is(err, io.EOF) {
+		return POM{}, fmt.Errorf("unable to decode %s\n%w", path, err)
+	}
+
+	return pom, nil
+}
+
+var expression = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Interpolate replaces the project coordinate and property expressions in s with their values.  Expressions that
+// cannot be resolved from the POM alone are left in place.
+func (p POM) Interpolate(s string) string {
+	return expression.ReplaceAllStringFunc(s, func(e string) string {
+		name := strings.TrimPrefix(strings.TrimPrefix(e[2:len(e)-1], "project."), "pom.")
+
+		var v string
+		switch name {
+		case "groupId":
+			v = p.EffectiveGroupID()
+		case "artifactId":
+			v = p.ArtifactID
+		case "version":
+			v = p.EffectiveVersion()
+		default:
+			v = p.Properties[e[2:len(e)-1]]
+		}
+
+		if v == "" || strings.Contains(v, e) {
+			return e
+		}
+		return v
+	})
+}
+
+// EffectiveGroupID returns the groupId of the POM, inherited from its parent if not declared.
+func (p POM) EffectiveGroupID() string {
+	if p.GroupID != "" {
+		return p.GroupID
+	}
+	return p.Parent.GroupID
+}
+
+// EffectiveVersion returns the version of the POM, inherited from its parent if not declared.
+func (p POM) EffectiveVersion() string {
+	if p.Version != "" {
+		return p.Version
+	}
+	return p.Parent.Version
+}
+
+// Plugin returns the plugin with the given artifactId, and whether it is declared.
+func (p POM) Plugin(artifactID string) (Plugin, bool) {
+	for _, plugin := range p.Plugins {
+		if plugin.ArtifactID == artifactID {
+			return plugin, true
+		}
+	}
+	return Plugin{}, false
+}
+
+// Executable returns whether the POM builds an executable application artifact: a war, a Spring Boot or Quarkus
+// application, or a shaded jar with a Main-Class.
+func (p POM) Executable() bool {
+	if p.Packaging == "war" {
+		return true
+	}
+
+	for _, id := range []string{"spring-boot-maven-plugin", "quarkus-maven-plugin"} {
+		if _, ok := p.Plugin(id); ok {
+			return true
+		}
+	}
+
+	if plugin, ok := p.Plugin("maven-shade-plugin"); ok {
+		if plugin.Configuration.MainClass != "" {
+			return true
+		}
+		for _, e := range plugin.Executions {
+			if e.Configuration.MainClass != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testPOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "pom")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("returns empty POM if file does not exist", func() {
+		Expect(maven.ReadPOM(filepath.Join(path, "pom.xml"))).To(Equal(maven.POM{}))
+	})
+
+	it("reads POM", func() {
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.xml"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>org.example</groupId>
+  <artifactId>test-artifact</artifactId>
+  <version>1.0.0</version>
+  <packaging>war</packaging>
+  <properties>
+    <java.version>17</java.version>
+  </properties>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>org.apache.maven.plugins</groupId>
+        <artifactId>maven-war-plugin</artifactId>
+        <version>3.3.2</version>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+		Expect(maven.ReadPOM(filepath.Join(path, "pom.xml"))).To(Equal(maven.POM{
+			ModelVersion: "4.0.0",
+			GroupID:      "org.example",
+			ArtifactID:   "test-artifact",
+			Version:      "1.0.0",
+			Packaging:    "war",
+			Properties:   maven.Properties{"java.version": "17"},
+			Plugins: []maven.Plugin{
+				{GroupID: "org.apache.maven.plugins", ArtifactID: "maven-war-plugin", Version: "3.3.2"},
+			},
+		}))
+	})
+
+	it("reads POM in the encoding of its XML declaration", func() {
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.xml"), []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n"+
+			"<project><artifactId>caf\xe9</artifactId></project>"), 0644)).To(Succeed())
+
+		Expect(maven.ReadPOM(filepath.Join(path, "pom.xml"))).To(Equal(maven.POM{ArtifactID: "caf\u00e9"}))
+	})
+
+	it("fails on invalid POM", func() {
+		Expect(ioutil.WriteFile(filepath.Join(path, "pom.xml"), []byte("<project>"), 0644)).To(Succeed())
+
+		_, err := maven.ReadPOM(filepath.Join(path, "pom.xml"))
+		Expect(err).To(MatchError(ContainSubstring("unable to decode")))
+	})
+
+	it("interpolates expressions", func() {
+		pom := maven.POM{
+			Parent:     maven.Parent{GroupID: "org.example", Version: "1.0.0"},
+			ArtifactID: "test-artifact",
+			Properties: maven.Properties{"suffix": "test-suffix"},
+		}
+
+		Expect(pom.Interpolate("${project.groupId}:${artifactId}:${project.version}-${suffix}-${revision}")).
+			To(Equal("org.example:test-artifact:1.0.0-test-suffix-${revision}"))
+	})
+
+	context("Executable", func() {
+		it("is not executable by default", func() {
+			Expect(maven.POM{}.Executable()).To(BeFalse())
+		})
+
+		it("is executable with war packaging", func() {
+			Expect(maven.POM{Packaging: "war"}.Executable()).To(BeTrue())
+		})
+
+		it("is executable with Spring Boot plugin", func() {
+			Expect(maven.POM{Plugins: []maven.Plugin{{ArtifactID: "spring-boot-maven-plugin"}}}.Executable()).To(BeTrue())
+		})
+
+		it("is executable with shaded Main-Class", func() {
+			Expect(ioutil.WriteFile(filepath.Join(path, "pom.xml"), []byte(`<project>
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-shade-plugin</artifactId>
+        <executions>
+          <execution>
+            <goals><goal>shade</goal></goals>
+            <configuration>
+              <transformers>
+                <transformer implementation="org.apache.maven.plugins.shade.resource.ManifestResourceTransformer">
+                  <mainClass>org.example.Main</mainClass>
+                </transformer>
+              </transformers>
+            </configuration>
+          </execution>
+        </executions>
+      </plugin>
+    </plugins>
+  </build>
+</project>`), 0644)).To(Succeed())
+
+			pom, err := maven.ReadPOM(filepath.Join(path, "pom.xml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pom.Executable()).To(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+// MultiplePOMsError is returned when POM discovery finds more than one project.
+type MultiplePOMsError struct {
+	Paths []string
+}
+
+func (m MultiplePOMsError) Error() string {
+	return fmt.Sprintf("found multiple POMs %s, set $BP_MAVEN_PROJECT_PATH to select one", m.Paths)
+}
+
+// ProjectPath returns the directory, relative to the application root, that Maven runs in: $BP_MAVEN_PROJECT_PATH if
+// set, otherwise the directory of $BP_MAVEN_POM_FILE if set, otherwise the directory of the single pom.xml found within
+// $BP_MAVEN_POM_DISCOVERY_DEPTH directories if the application root has no POM, otherwise the application root.
+func ProjectPath(cr libpak.ConfigurationResolver, applicationPath string) (string, error) {
+	if p, ok := cr.Resolve("BP_MAVEN_PROJECT_PATH"); ok {
+		p, err := relative(applicationPath, p)
+		if err != nil {
+			return "", fmt.Errorf("project path %w", err)
+		}
+
+		return p, nil
+	}
+
+	pomFile, pomFileSet := cr.Resolve("BP_MAVEN_POM_FILE")
+	if pomFileSet {
+		p, err := relative(applicationPath, pomFile)
+		if err != nil {
+			return "", fmt.Errorf("POM %w", err)
+		}
+
+		return filepath.Dir(p), nil
+	}
+
+	s, _ := cr.Resolve("BP_MAVEN_POM_DISCOVERY_DEPTH")
+	if s == "" {
+		return ".", nil
+	}
+
+	depth, err := strconv.Atoi(s)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse $BP_MAVEN_POM_DISCOVERY_DEPTH %s\n%w", s, err)
+	}
+
+	if depth <= 0 {
+		return ".", nil
+	}
+
+	if _, err := os.Stat(filepath.Join(applicationPath, pomFile)); err == nil {
+		return ".", nil
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("unable to determine if %s exists\n%w", pomFile, err)
+	}
+
+	if _, ok, err := PolyglotPOM(applicationPath); err != nil {
+		return "", fmt.Errorf("unable to find polyglot POM\n%w", err)
+	} else if ok {
+		return ".", nil
+	}
+
+	poms, err := FindPOMs(applicationPath, filepath.Base(pomFile), depth)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(poms) {
+	case 0:
+		return ".", nil
+	case 1:
+		return filepath.Dir(poms[0]), nil
+	default:
+		return "", MultiplePOMsError{Paths: poms}
+	}
+}
+
+// Wrapper returns the path of the Maven Wrapper nearest to project, searching from project up to the application root.
+// Returns false if there is no Maven Wrapper.
+func Wrapper(applicationPath string, project string) (string, bool, error) {
+	for dir := project; ; dir = filepath.Dir(dir) {
+		file := filepath.Join(applicationPath, dir, "mvnw")
+		if _, err := os.Stat(file); err == nil {
+			return file, true, nil
+		} else if !os.IsNotExist(err) {
+			return "", false, fmt.Errorf("unable to stat %s\n%w", file, err)
+		}
+
+		if dir == "." {
+			return "", false, nil
+		}
+	}
+}
+
+// relative returns path, absolute or relative to applicationPath, relative to applicationPath.  Returns an error if path
+// is not within applicationPath.
+func relative(applicationPath string, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		r, err := filepath.Rel(applicationPath, path)
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve %s relative to %s\n%w", path, applicationPath, err)
+		}
+		path = r
+	}
+
+	path = filepath.Clean(path)
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("%s is not within the application", path)
+	}
+
+	return path, nil
+}
+
+// FindPOMs returns the POMs named name, relative to applicationPath, within depth directories of applicationPath.  The
+// directories of a POM, its modules, and hidden and build output directories are not searched.
+func FindPOMs(applicationPath string, name string, depth int) ([]string, error) {
+	var poms []string
+
+	err := filepath.WalkDir(applicationPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(applicationPath, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), ".") || d.Name() == "target" || d.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			poms = append(poms, filepath.Join(rel, name))
+			return filepath.SkipDir
+		}
+
+		if strings.Count(rel, string(filepath.Separator))+1 >= depth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to search for %s in %s\n%w", name, applicationPath, err)
+	}
+
+	return poms, nil
+}
+
+// WorkingDirectoryExecutor is an effect.Executor that runs executions in Directory.
+type WorkingDirectoryExecutor struct {
+	Delegate  effect.Executor
+	Directory string
+}
+
+func (w WorkingDirectoryExecutor) Execute(execution effect.Execution) error {
+	execution.Dir = w.Directory
+	return w.Delegate.Execute(execution)
+}
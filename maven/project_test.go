@@ -0,0 +1,209 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testProject(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr   libpak.ConfigurationResolver
+		path string
+	)
+
+	writePOM := func(dir string) {
+		Expect(os.MkdirAll(filepath.Join(path, dir), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, dir, "pom.xml"), []byte("<project/>"), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "project")
+		Expect(err).NotTo(HaveOccurred())
+
+		cr = libpak.ConfigurationResolver{Configurations: []libpak.BuildpackConfiguration{
+			{Name: "BP_MAVEN_POM_FILE", Default: "pom.xml"},
+			{Name: "BP_MAVEN_POM_DISCOVERY_DEPTH", Default: "0"},
+		}}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("defaults to the application root", func() {
+		writePOM("services/shop")
+
+		Expect(maven.ProjectPath(cr, path)).To(Equal("."))
+	})
+
+	context("$BP_MAVEN_PROJECT_PATH", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_PROJECT_PATH")).To(Succeed())
+		})
+
+		it("uses a relative path", func() {
+			Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", "services/shop/")).To(Succeed())
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services/shop"))
+		})
+
+		it("uses an absolute path within the application", func() {
+			Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", filepath.Join(path, "services", "shop"))).To(Succeed())
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services/shop"))
+		})
+
+		it("fails with a path outside the application", func() {
+			Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", "../shop")).To(Succeed())
+
+			_, err := maven.ProjectPath(cr, path)
+			Expect(err).To(MatchError("project path ../shop is not within the application"))
+		})
+	})
+
+	context("$BP_MAVEN_POM_FILE", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_POM_FILE")).To(Succeed())
+			Expect(os.Unsetenv("BP_MAVEN_PROJECT_PATH")).To(Succeed())
+		})
+
+		it("uses the directory of the POM", func() {
+			Expect(os.Setenv("BP_MAVEN_POM_FILE", "services/shop/pom.xml")).To(Succeed())
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services/shop"))
+		})
+
+		it("uses the directory of an absolute POM", func() {
+			Expect(os.Setenv("BP_MAVEN_POM_FILE", filepath.Join(path, "services", "shop", "pom.xml"))).To(Succeed())
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services/shop"))
+		})
+
+		it("prefers $BP_MAVEN_PROJECT_PATH", func() {
+			Expect(os.Setenv("BP_MAVEN_POM_FILE", "services/shop/pom.xml")).To(Succeed())
+			Expect(os.Setenv("BP_MAVEN_PROJECT_PATH", "services")).To(Succeed())
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services"))
+		})
+	})
+
+	context("$BP_MAVEN_POM_DISCOVERY_DEPTH", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_POM_DISCOVERY_DEPTH", "2")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_POM_DISCOVERY_DEPTH")).To(Succeed())
+		})
+
+		it("prefers a POM in the application root", func() {
+			writePOM(".")
+			writePOM("services/shop")
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("."))
+		})
+
+		it("discovers a single POM", func() {
+			writePOM("services/shop")
+			writePOM("services/shop/app")
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("services/shop"))
+		})
+
+		it("does not discover POMs beyond the depth", func() {
+			writePOM("services/shop/app")
+
+			Expect(maven.ProjectPath(cr, path)).To(Equal("."))
+		})
+
+		it("fails with several POMs", func() {
+			writePOM("services/billing")
+			writePOM("services/shop")
+
+			_, err := maven.ProjectPath(cr, path)
+			Expect(err).To(Equal(maven.MultiplePOMsError{Paths: []string{
+				"services/billing/pom.xml",
+				"services/shop/pom.xml",
+			}}))
+			Expect(err).To(MatchError("found multiple POMs [services/billing/pom.xml services/shop/pom.xml], " +
+				"set $BP_MAVEN_PROJECT_PATH to select one"))
+		})
+	})
+
+	it("ignores hidden and build output directories", func() {
+		writePOM(".git/shop")
+		writePOM("target/shop")
+		writePOM("node_modules/shop")
+		writePOM("shop")
+
+		Expect(maven.FindPOMs(path, "pom.xml", 2)).To(Equal([]string{"shop/pom.xml"}))
+	})
+
+	context("Wrapper", func() {
+		it("returns the Maven Wrapper of the project", func() {
+			writePOM("services/shop")
+			Expect(ioutil.WriteFile(filepath.Join(path, "mvnw"), []byte{}, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, "services", "shop", "mvnw"), []byte{}, 0755)).To(Succeed())
+
+			file, ok, err := maven.Wrapper(path, "services/shop")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(path, "services", "shop", "mvnw")))
+		})
+
+		it("returns the Maven Wrapper of a parent directory", func() {
+			writePOM("services/shop")
+			Expect(ioutil.WriteFile(filepath.Join(path, "mvnw"), []byte{}, 0755)).To(Succeed())
+
+			file, ok, err := maven.Wrapper(path, "services/shop")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(path, "mvnw")))
+		})
+
+		it("returns false without a Maven Wrapper", func() {
+			writePOM("services/shop")
+
+			_, ok, err := maven.Wrapper(path, "services/shop")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	it("runs executions in the working directory", func() {
+		delegate := &FakeExecutor{}
+
+		Expect(maven.WorkingDirectoryExecutor{Delegate: delegate, Directory: path}.
+			Execute(effect.Execution{Dir: "test-dir"})).To(Succeed())
+		Expect(delegate.Executions[0].Dir).To(Equal(path))
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// ProxySettingsFile is the name of the settings file, in the ProxySettings layer, configuring the proxies.
+const ProxySettingsFile = "settings.xml"
+
+// Proxy is a proxy server of a Maven settings file, used for the repositories of Protocol.
+type Proxy struct {
+	ID            string `xml:"id"`
+	Protocol      string `xml:"protocol"`
+	Host          string `xml:"host"`
+	Port          int    `xml:"port"`
+	Username      string `xml:"username,omitempty"`
+	Password      string `xml:"password,omitempty"`
+	NonProxyHosts string `xml:"nonProxyHosts,omitempty"`
+}
+
+// Proxies returns the proxies configured by $HTTP_PROXY and $HTTPS_PROXY, or their lower case forms, which Maven
+// ignores, bypassed for the hosts of $NO_PROXY.
+func Proxies() ([]Proxy, error) {
+	nonProxyHosts := NonProxyHosts(getenv("NO_PROXY"))
+
+	var proxies []Proxy
+	for _, protocol := range []string{"http", "https"} {
+		name := fmt.Sprintf("%s_PROXY", strings.ToUpper(protocol))
+
+		s := getenv(name)
+		if s == "" {
+			continue
+		}
+
+		// the value is not reported as it may contain the credentials of the proxy
+		p, ok := parseProxy(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid $%s, must be a URL", name)
+		}
+
+		p.ID = fmt.Sprintf("%s-proxy", protocol)
+		p.Protocol = protocol
+		p.NonProxyHosts = nonProxyHosts
+		proxies = append(proxies, p)
+	}
+
+	return proxies, nil
+}
+
+// NonProxyHosts translates a $NO_PROXY list of domains into the nonProxyHosts of Maven: a domain also matches its
+// subdomains, and a leading . or *. is replaced by a wildcard.  Ports are removed and CIDR ranges, which cannot be
+// expressed, are ignored.
+func NonProxyHosts(noProxy string) string {
+	var hosts []string
+	seen := map[string]bool{}
+
+	add := func(host string) {
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	for _, s := range strings.Split(noProxy, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.Contains(s, "/") {
+			continue
+		}
+
+		if host, _, err := net.SplitHostPort(s); err == nil {
+			s = host
+		}
+
+		if s == "*" {
+			add(s)
+			continue
+		}
+
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "*"), ".")
+		add(s)
+		if net.ParseIP(s) == nil {
+			add(fmt.Sprintf("*.%s", s))
+		}
+	}
+
+	return strings.Join(hosts, "|")
+}
+
+// SettingsProxies returns whether the settings file at path configures proxies.
+func SettingsProxies(path string) (bool, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer in.Close()
+
+	var settings struct {
+		Proxies []struct{} `xml:"proxies>proxy"`
+	}
+	if err := xml.NewDecoder(in).Decode(&settings); err != nil {
+		return false, fmt.Errorf("unable to decode %s\n%w", path, err)
+	}
+
+	return len(settings.Proxies) > 0, nil
+}
+
+// ProxySettings contributes a layer containing a settings file that configures the proxies of the environment.  It is
+// passed to Maven as its global settings so that the user settings, and any proxies they configure, take precedence.
+type ProxySettings struct {
+	Logger  bard.Logger
+	Proxies []Proxy
+}
+
+func (p ProxySettings) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.RemoveAll(layer.Path); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", layer.Path, err)
+	}
+
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer directory %s\n%w", layer.Path, err)
+	}
+
+	b, err := xml.MarshalIndent(struct {
+		XMLName xml.Name `xml:"settings"`
+		Proxies []Proxy  `xml:"proxies>proxy"`
+	}{Proxies: p.Proxies}, "", "  ")
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to encode proxy settings\n%w", err)
+	}
+
+	var protocols []string
+	for _, proxy := range p.Proxies {
+		protocols = append(protocols, proxy.Protocol)
+	}
+	p.Logger.Bodyf("Configuring proxies for %s from the environment", strings.Join(protocols, ", "))
+
+	file := filepath.Join(layer.Path, ProxySettingsFile)
+
+	// the settings may contain the credentials of the proxies
+	if err := ioutil.WriteFile(file, append([]byte(xml.Header), b...), 0600); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return layer, nil
+}
+
+func (ProxySettings) Name() string {
+	return "proxy-settings"
+}
+
+func parseProxy(s string) (Proxy, bool) {
+	if !strings.Contains(s, "://") {
+		s = fmt.Sprintf("http://%s", s)
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return Proxy{}, false
+	}
+
+	p := Proxy{Host: u.Hostname(), Port: 80}
+	if u.Scheme == "https" {
+		p.Port = 443
+	}
+
+	if s := u.Port(); s != "" {
+		if p.Port, err = strconv.Atoi(s); err != nil {
+			return Proxy{}, false
+		}
+	}
+
+	if u.User != nil {
+		p.Username = u.User.Username()
+		p.Password, _ = u.User.Password()
+	}
+
+	return p, true
+}
+
+// globalSettings returns whether arguments set the global settings file.
+func globalSettings(arguments []string) bool {
+	for _, a := range arguments {
+		if a == "-gs" || a == "--global-settings" || strings.HasPrefix(a, "--global-settings=") {
+			return true
+		}
+	}
+	return false
+}
+
+// getenv returns the environment variable name, or its lower case form, as used by curl and Go.
+func getenv(name string) string {
+	if s, ok := os.LookupEnv(name); ok && s != "" {
+		return s
+	}
+	return os.Getenv(strings.ToLower(name))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApplicationModules returns the directories, relative to the application root, of the projects in the reactor rooted
+// at pomFile that build an executable application artifact.  Modules are traversed recursively.  Returns nil if the
+// root POM does not declare any modules.
+func ApplicationModules(applicationPath string, pomFile string) ([]string, error) {
+	var modules []string
+
+	root, err := walkReactor(applicationPath, pomFile, func(dir string, pom POM) {
+		if pom.Executable() {
+			modules = append(modules, dir)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(root.Modules) == 0 {
+		return nil, nil
+	}
+
+	return modules, nil
+}
+
+// HasSources returns whether any project in the reactor rooted at pomFile has a src directory.
+func HasSources(applicationPath string, pomFile string) (bool, error) {
+	sources := false
+
+	if _, err := walkReactor(applicationPath, pomFile, func(dir string, _ POM) {
+		if fi, err := os.Stat(filepath.Join(applicationPath, dir, "src")); err == nil && fi.IsDir() {
+			sources = true
+		}
+	}); err != nil {
+		return false, err
+	}
+
+	return sources, nil
+}
+
+// walkReactor calls f with the directory, relative to the application root, and the POM of each project in the
+// reactor rooted at pomFile, traversing modules recursively.  Returns the root POM.
+func walkReactor(applicationPath string, pomFile string, f func(dir string, pom POM)) (POM, error) {
+	root, err := ReadPOM(filepath.Join(applicationPath, pomFile))
+	if err != nil {
+		return POM{}, err
+	}
+
+	visited := map[string]bool{}
+
+	var walk func(dir string, pom POM) error
+	walk = func(dir string, pom POM) error {
+		if visited[dir] {
+			return nil
+		}
+		visited[dir] = true
+
+		f(dir, pom)
+
+		for _, m := range pom.Modules {
+			file := filepath.Join(dir, m)
+			if !strings.HasSuffix(m, ".xml") {
+				file = filepath.Join(file, "pom.xml")
+			}
+
+			p, err := ReadPOM(filepath.Join(applicationPath, file))
+			if err != nil {
+				return fmt.Errorf("unable to read module %s\n%w", m, err)
+			}
+
+			if err := walk(filepath.Dir(file), p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(filepath.Dir(pomFile), root); err != nil {
+		return POM{}, err
+	}
+
+	return root, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testReactor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	writePOM := func(file string, content string) {
+		Expect(os.MkdirAll(filepath.Dir(filepath.Join(path, file)), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, file), []byte(content), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "reactor")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("returns nil without modules", func() {
+		writePOM("pom.xml", `<project><artifactId>test-artifact</artifactId></project>`)
+
+		Expect(maven.ApplicationModules(path, "pom.xml")).To(BeNil())
+	})
+
+	it("returns modules building executable artifacts", func() {
+		writePOM("pom.xml", `<project>
+  <packaging>pom</packaging>
+  <modules>
+    <module>lib</module>
+    <module>app</module>
+    <module>nested</module>
+  </modules>
+</project>`)
+		writePOM("lib/pom.xml", `<project><artifactId>lib</artifactId></project>`)
+		writePOM("app/pom.xml", `<project>
+  <artifactId>app</artifactId>
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-maven-plugin</artifactId>
+      </plugin>
+    </plugins>
+  </build>
+</project>`)
+		writePOM("nested/pom.xml", `<project>
+  <packaging>pom</packaging>
+  <modules>
+    <module>web/custom-pom.xml</module>
+  </modules>
+</project>`)
+		writePOM("nested/web/custom-pom.xml", `<project>
+  <artifactId>web</artifactId>
+  <packaging>war</packaging>
+</project>`)
+
+		Expect(maven.ApplicationModules(path, "pom.xml")).To(Equal([]string{"app", "nested/web"}))
+	})
+
+	it("returns modules relative to the application root", func() {
+		writePOM("services/pom.xml", `<project>
+  <modules>
+    <module>app</module>
+  </modules>
+</project>`)
+		writePOM("services/app/pom.xml", `<project>
+  <artifactId>app</artifactId>
+  <packaging>war</packaging>
+</project>`)
+
+		Expect(maven.ApplicationModules(path, "services/pom.xml")).To(Equal([]string{"services/app"}))
+	})
+
+	context("HasSources", func() {
+		it("returns false without sources", func() {
+			writePOM("pom.xml", `<project><modules><module>app</module></modules></project>`)
+
+			Expect(maven.HasSources(path, "pom.xml")).To(BeFalse())
+		})
+
+		it("returns true with root sources", func() {
+			writePOM("pom.xml", `<project><artifactId>test-artifact</artifactId></project>`)
+			Expect(os.MkdirAll(filepath.Join(path, "src"), 0755)).To(Succeed())
+
+			Expect(maven.HasSources(path, "pom.xml")).To(BeTrue())
+		})
+
+		it("returns true with nested module sources", func() {
+			writePOM("pom.xml", `<project><modules><module>services</module></modules></project>`)
+			writePOM("services/pom.xml", `<project><modules><module>app</module></modules></project>`)
+			Expect(os.MkdirAll(filepath.Join(path, "services", "app", "src"), 0755)).To(Succeed())
+
+			Expect(maven.HasSources(path, "pom.xml")).To(BeTrue())
+		})
+	})
+}
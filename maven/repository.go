@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bindings"
+)
+
+// settingsExpression matches the ${...} expressions of a settings file.
+var settingsExpression = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// BindingTypeMavenRepository is the type of bindings whose directory is a read-only base repository.
+const BindingTypeMavenRepository = "maven-repository"
+
+// DefaultRepositoryPath is the location of the local repository relative to the Maven user home.
+const DefaultRepositoryPath = "repository"
+
+// MavenUserHome returns the directory that the cache layer is linked to: $MAVEN_USER_HOME if set, ~/.m2 if the build
+// user has a home directory, and the cache layer itself, in layersPath, otherwise.
+func MavenUserHome(layersPath string) string {
+	if s, ok := os.LookupEnv("MAVEN_USER_HOME"); ok && s != "" {
+		return s
+	}
+
+	if u, err := user.Current(); err == nil && u.HomeDir != "" {
+		return filepath.Join(u.HomeDir, ".m2")
+	}
+
+	return filepath.Join(layersPath, "cache")
+}
+
+// RepositoryPath returns the local repository that Maven resolves artifacts into: $BP_MAVEN_REPOSITORY_PATH, relative
+// to userHome unless absolute.
+func RepositoryPath(cr libpak.ConfigurationResolver, userHome string) string {
+	path, _ := cr.Resolve("BP_MAVEN_REPOSITORY_PATH")
+	if path == "" {
+		path = DefaultRepositoryPath
+	}
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(userHome, path)
+}
+
+// BaseRepositories returns the read-only repositories chained behind the local repository, so that the artifacts they
+// contain are not downloaded: $BP_MAVEN_BASE_REPOSITORY, e.g. a volume shared by builds, and the directories of the
+// maven-repository bindings.
+func BaseRepositories(cr libpak.ConfigurationResolver, binds libcnb.Bindings) ([]string, error) {
+	var repositories []string
+
+	if path, ok := cr.Resolve("BP_MAVEN_BASE_REPOSITORY"); ok && path != "" {
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			return nil, fmt.Errorf("unable to find base repository %s", path)
+		}
+		repositories = append(repositories, path)
+	}
+
+	for _, b := range bindings.Resolve(binds, bindings.OfType(BindingTypeMavenRepository)) {
+		repositories = append(repositories, b.Path)
+	}
+
+	return repositories, nil
+}
+
+// SettingsLocalRepository returns the local repository configured by the settings file at path, with ${user.home} and
+// ${env.*} expanded.  Returns false if the settings do not configure a local repository.
+func SettingsLocalRepository(path string) (string, bool, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer in.Close()
+
+	var settings struct {
+		LocalRepository string `xml:"localRepository"`
+	}
+	if err := xml.NewDecoder(in).Decode(&settings); err != nil {
+		return "", false, fmt.Errorf("unable to decode %s\n%w", path, err)
+	}
+
+	local := strings.TrimSpace(settings.LocalRepository)
+	if local == "" {
+		return "", false, nil
+	}
+
+	local = settingsExpression.ReplaceAllStringFunc(local, func(e string) string {
+		name := settingsExpression.FindStringSubmatch(e)[1]
+		if name == "user.home" {
+			if u, err := user.Current(); err == nil {
+				return u.HomeDir
+			}
+		} else if strings.HasPrefix(name, "env.") {
+			return os.Getenv(strings.TrimPrefix(name, "env."))
+		}
+		return e
+	})
+
+	return local, true, nil
+}
+
+// property returns the value of the system property name defined by arguments, and whether it is defined.
+func property(arguments []string, name string) (string, bool) {
+	for _, a := range arguments {
+		if a == "-D"+name {
+			return "", true
+		} else if strings.HasPrefix(a, "-D"+name+"=") {
+			return strings.TrimPrefix(a, "-D"+name+"="), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testRepository(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("MavenUserHome", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("MAVEN_USER_HOME")).To(Succeed())
+		})
+
+		it("uses $MAVEN_USER_HOME", func() {
+			Expect(os.Setenv("MAVEN_USER_HOME", "/test/maven")).To(Succeed())
+
+			Expect(maven.MavenUserHome("/layers")).To(Equal("/test/maven"))
+		})
+
+		it("uses ~/.m2", func() {
+			Expect(maven.MavenUserHome("/layers")).To(HaveSuffix("/.m2"))
+		})
+	})
+
+	context("BaseRepositories", func() {
+		var path string
+
+		it.Before(func() {
+			var err error
+
+			path, err = ioutil.TempDir("", "repository")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_BASE_REPOSITORY")).To(Succeed())
+			Expect(os.RemoveAll(path)).To(Succeed())
+		})
+
+		it("returns $BP_MAVEN_BASE_REPOSITORY and maven-repository bindings", func() {
+			Expect(os.Setenv("BP_MAVEN_BASE_REPOSITORY", path)).To(Succeed())
+
+			Expect(maven.BaseRepositories(libpak.ConfigurationResolver{}, libcnb.Bindings{
+				{Name: "settings", Type: "maven", Path: "/bindings/settings"},
+				{Name: "shared", Type: "maven-repository", Path: "/bindings/shared"},
+			})).To(Equal([]string{path, "/bindings/shared"}))
+		})
+
+		it("fails if $BP_MAVEN_BASE_REPOSITORY does not exist", func() {
+			Expect(os.Setenv("BP_MAVEN_BASE_REPOSITORY", filepath.Join(path, "missing"))).To(Succeed())
+
+			_, err := maven.BaseRepositories(libpak.ConfigurationResolver{}, nil)
+			Expect(err).To(MatchError(fmt.Sprintf("unable to find base repository %s", filepath.Join(path, "missing"))))
+		})
+	})
+
+	context("SettingsLocalRepository", func() {
+		var path string
+
+		it.Before(func() {
+			f, err := ioutil.TempFile("", "settings")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			path = f.Name()
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(path)).To(Succeed())
+			Expect(os.Unsetenv("TEST_REPOSITORY")).To(Succeed())
+		})
+
+		it("returns the local repository of the settings", func() {
+			Expect(os.Setenv("TEST_REPOSITORY", "/test-repository")).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(`<settings>
+  <localRepository>${env.TEST_REPOSITORY}/maven</localRepository>
+</settings>`), 0644)).To(Succeed())
+
+			local, ok, err := maven.SettingsLocalRepository(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(local).To(Equal("/test-repository/maven"))
+		})
+
+		it("returns false if the settings do not configure a local repository", func() {
+			Expect(ioutil.WriteFile(path, []byte(`<settings><mirrors/></settings>`), 0644)).To(Succeed())
+
+			_, ok, err := maven.SettingsLocalRepository(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	context("RepositoryPath", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_REPOSITORY_PATH")).To(Succeed())
+		})
+
+		it("defaults to the repository of the Maven user home", func() {
+			Expect(maven.RepositoryPath(libpak.ConfigurationResolver{}, "/home/test/.m2")).
+				To(Equal("/home/test/.m2/repository"))
+		})
+
+		it("resolves a relative path against the Maven user home", func() {
+			Expect(os.Setenv("BP_MAVEN_REPOSITORY_PATH", "shared/repository")).To(Succeed())
+
+			Expect(maven.RepositoryPath(libpak.ConfigurationResolver{}, "/home/test/.m2")).
+				To(Equal("/home/test/.m2/shared/repository"))
+		})
+
+		it("uses an absolute path", func() {
+			Expect(os.Setenv("BP_MAVEN_REPOSITORY_PATH", "/repository")).To(Succeed())
+
+			Expect(maven.RepositoryPath(libpak.ConfigurationResolver{}, "/home/test/.m2")).To(Equal("/repository"))
+		})
+	})
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+const OutputTimestampProperty = "project.build.outputTimestamp"
+
+// reproduciblePlugins are the minimum versions of plugins that honor project.build.outputTimestamp.
+var reproduciblePlugins = map[string]string{
+	"maven-assembly-plugin":    "3.2.0",
+	"maven-ear-plugin":         "3.1.0",
+	"maven-jar-plugin":         "3.2.0",
+	"maven-source-plugin":      "3.2.1",
+	"maven-war-plugin":         "3.3.1",
+	"spring-boot-maven-plugin": "2.3.0",
+}
+
+// OutputTimestamp resolves the value for project.build.outputTimestamp.  $SOURCE_DATE_EPOCH is used if set, otherwise
+// the time of the last git commit if $BP_MAVEN_REPRODUCIBLE is true.  Returns false if neither is configured.
+func OutputTimestamp(cr libpak.ConfigurationResolver, applicationPath string, executor effect.Executor) (string, bool, error) {
+	s, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok {
+		if !cr.ResolveBool("BP_MAVEN_REPRODUCIBLE") {
+			return "", false, nil
+		}
+
+		buf := &bytes.Buffer{}
+		if err := executor.Execute(effect.Execution{
+			Command: "git",
+			Args:    []string{"log", "-1", "--format=%ct"},
+			Dir:     applicationPath,
+			Stdout:  buf,
+			Stderr:  buf,
+		}); err != nil {
+			return "", false, fmt.Errorf("unable to determine time of last commit, set $SOURCE_DATE_EPOCH instead\n%s\n%w", buf.String(), err)
+		}
+		s = buf.String()
+	}
+
+	epoch, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to parse %q as seconds since epoch\n%w", s, err)
+	}
+
+	return time.Unix(epoch, 0).UTC().Format(time.RFC3339), true, nil
+}
+
+// ReproducibilityWarnings lists the plugins declared in a POM at versions that do not honor
+// project.build.outputTimestamp.
+func ReproducibilityWarnings(pom POM) []string {
+	var warnings []string
+
+	for _, p := range pom.Plugins {
+		minimum, ok := reproduciblePlugins[p.ArtifactID]
+		if !ok || p.Version == "" {
+			continue
+		}
+
+		v, err := semver.NewVersion(p.Version)
+		if err != nil {
+			continue
+		}
+
+		if v.LessThan(semver.MustParse(minimum)) {
+			warnings = append(warnings, fmt.Sprintf("%s %s does not support reproducible builds, %s or later is required",
+				p.ArtifactID, p.Version, minimum))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testReproducible(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr       libpak.ConfigurationResolver
+		executor *FakeExecutor
+	)
+
+	it.Before(func() {
+		executor = &FakeExecutor{}
+	})
+
+	it("does not resolve a timestamp by default", func() {
+		_, ok, err := maven.OutputTimestamp(cr, "test-path", executor)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(executor.Executions).To(BeEmpty())
+	})
+
+	context("SOURCE_DATE_EPOCH is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("SOURCE_DATE_EPOCH", "1640995200")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+		})
+
+		it("resolves timestamp from SOURCE_DATE_EPOCH", func() {
+			timestamp, ok, err := maven.OutputTimestamp(cr, "test-path", executor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(timestamp).To(Equal("2022-01-01T00:00:00Z"))
+		})
+	})
+
+	context("BP_MAVEN_REPRODUCIBLE is true", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_REPRODUCIBLE", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_REPRODUCIBLE")).To(Succeed())
+		})
+
+		it("resolves timestamp from the last commit", func() {
+			executor.Stdout = "1640995200\n"
+
+			timestamp, ok, err := maven.OutputTimestamp(cr, "test-path", executor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(timestamp).To(Equal("2022-01-01T00:00:00Z"))
+
+			Expect(executor.Executions).To(HaveLen(1))
+			Expect(executor.Executions[0].Command).To(Equal("git"))
+			Expect(executor.Executions[0].Dir).To(Equal("test-path"))
+		})
+	})
+
+	it("warns about plugins that do not support reproducible builds", func() {
+		Expect(maven.ReproducibilityWarnings(maven.POM{
+			Plugins: []maven.Plugin{
+				{ArtifactID: "maven-jar-plugin", Version: "3.1.2"},
+				{ArtifactID: "maven-war-plugin", Version: "3.3.2"},
+				{ArtifactID: "maven-compiler-plugin", Version: "3.1"},
+			},
+		})).To(Equal([]string{
+			"maven-jar-plugin 3.1.2 does not support reproducible builds, 3.2.0 or later is required",
+		}))
+	})
+}
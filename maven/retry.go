@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+// RetryBackoff is the delay before the first retry of a build, doubled for each further retry.
+const RetryBackoff = 10 * time.Second
+
+// RepositoryProperties are the HTTP retry and timeout properties of Maven Resolver, and of Wagon used by Maven before
+// 3.9, that are set unless the build arguments define them.
+var RepositoryProperties = []struct {
+	Name  string
+	Value string
+}{
+	{Name: "aether.connector.http.retryHandler.count", Value: "3"},
+	{Name: "aether.connector.connectTimeout", Value: "30000"},
+	{Name: "aether.connector.requestTimeout", Value: "300000"},
+	{Name: "maven.wagon.http.retryHandler.count", Value: "3"},
+}
+
+// transient matches the output of Maven that reports a repository failure that may succeed if retried.
+var transient = regexp.MustCompile(`(?i)(status code: (429|502|503|504)\b|` +
+	`\b(429 Too Many Requests|502 Bad Gateway|503 Service Unavailable|504 Gateway Time-?out)\b|` +
+	`Connection reset|connect timed out|Read timed out|Remote host terminated the handshake|` +
+	`Premature end of Content-Length)`)
+
+// Retries returns the number of times a build failing with a transient repository failure is retried.
+func Retries(cr libpak.ConfigurationResolver) (int, error) {
+	s, _ := cr.Resolve("BP_MAVEN_RETRIES")
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid $BP_MAVEN_RETRIES %s, must be a non-negative number", s)
+	}
+	return n, nil
+}
+
+// RepositoryArguments returns the arguments setting the RepositoryProperties not defined by arguments.
+func RepositoryArguments(arguments []string) []string {
+	var properties []string
+	for _, p := range RepositoryProperties {
+		if _, ok := property(arguments, p.Name); !ok {
+			properties = append(properties, fmt.Sprintf("-D%s=%s", p.Name, p.Value))
+		}
+	}
+	return properties
+}
+
+// RetryExecutor is an effect.Executor that retries a build failing with a transient repository failure reported in
+// its output, up to Retries times with exponential backoff.  Retries force Maven to check the remote repositories
+// again, rather than failing on the failure cached in the local repository.
+type RetryExecutor struct {
+	Backoff  time.Duration
+	Delegate effect.Executor
+	Logger   bard.Logger
+	Retries  int
+}
+
+func (r RetryExecutor) Execute(execution effect.Execution) error {
+	backoff := r.Backoff
+
+	for attempt := 0; ; attempt++ {
+		detector := &transientDetector{}
+
+		e := execution
+		e.Stdout = tee(execution.Stdout, detector)
+		e.Stderr = tee(execution.Stderr, detector)
+
+		err := r.Delegate.Execute(e)
+		if err == nil || !detector.Detected || attempt >= r.Retries {
+			return err
+		}
+
+		r.Logger.Bodyf("Transient repository failure, retrying build in %s (%d of %d)", backoff, attempt+1, r.Retries)
+		time.Sleep(backoff)
+		backoff *= 2
+
+		if attempt == 0 && !contains(execution.Args, []string{"-U", "--update-snapshots"}) {
+			execution.Args = append([]string{"--update-snapshots"}, execution.Args...)
+		}
+	}
+}
+
+// tee returns a writer writing to both w, if not nil, and observer.
+func tee(w io.Writer, observer io.Writer) io.Writer {
+	if w == nil {
+		return observer
+	}
+	return io.MultiWriter(w, observer)
+}
+
+// transientDetector is an io.Writer that detects transient repository failures line by line.
+type transientDetector struct {
+	Detected bool
+	line     []byte
+}
+
+func (t *transientDetector) Write(p []byte) (int, error) {
+	t.line = append(t.line, p...)
+
+	for {
+		i := bytes.IndexByte(t.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		if transient.Match(t.line[:i]) {
+			t.Detected = true
+		}
+		t.line = t.line[i+1:]
+	}
+
+	if transient.Match(t.line) {
+		t.Detected = true
+	}
+
+	return len(p), nil
+}
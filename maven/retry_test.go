@@ -0,0 +1,132 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testRetry(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("Retries", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_RETRIES")).To(Succeed())
+		})
+
+		it("does not retry by default", func() {
+			Expect(maven.Retries(libpak.ConfigurationResolver{})).To(Equal(0))
+		})
+
+		it("uses $BP_MAVEN_RETRIES", func() {
+			Expect(os.Setenv("BP_MAVEN_RETRIES", "2")).To(Succeed())
+
+			Expect(maven.Retries(libpak.ConfigurationResolver{})).To(Equal(2))
+		})
+
+		it("fails if $BP_MAVEN_RETRIES is not a number", func() {
+			Expect(os.Setenv("BP_MAVEN_RETRIES", "many")).To(Succeed())
+
+			_, err := maven.Retries(libpak.ConfigurationResolver{})
+			Expect(err).To(MatchError("invalid $BP_MAVEN_RETRIES many, must be a non-negative number"))
+		})
+	})
+
+	context("RepositoryArguments", func() {
+		it("returns the repository properties", func() {
+			Expect(maven.RepositoryArguments([]string{"package"})).To(Equal([]string{
+				"-Daether.connector.http.retryHandler.count=3",
+				"-Daether.connector.connectTimeout=30000",
+				"-Daether.connector.requestTimeout=300000",
+				"-Dmaven.wagon.http.retryHandler.count=3",
+			}))
+		})
+
+		it("does not return repository properties defined by the arguments", func() {
+			Expect(maven.RepositoryArguments([]string{
+				"-Daether.connector.http.retryHandler.count=5",
+				"-Daether.connector.requestTimeout=60000",
+				"package",
+			})).To(Equal([]string{
+				"-Daether.connector.connectTimeout=30000",
+				"-Dmaven.wagon.http.retryHandler.count=3",
+			}))
+		})
+	})
+
+	context("RetryExecutor", func() {
+		var (
+			delegate *FakeExecutor
+			executor maven.RetryExecutor
+		)
+
+		it.Before(func() {
+			delegate = &FakeExecutor{Err: os.ErrInvalid}
+			executor = maven.RetryExecutor{Delegate: delegate, Retries: 2}
+		})
+
+		it("does not retry a successful build", func() {
+			delegate.Err = nil
+			delegate.Stdout = "status code: 503, reason phrase: Service Unavailable\n"
+
+			Expect(executor.Execute(effect.Execution{Args: []string{"package"}, Stdout: &bytes.Buffer{}})).To(Succeed())
+			Expect(delegate.Executions).To(HaveLen(1))
+		})
+
+		it("does not retry a build failing without a transient repository failure", func() {
+			delegate.Stdout = "[ERROR] COMPILATION ERROR\n"
+
+			err := executor.Execute(effect.Execution{Args: []string{"package"}, Stdout: &bytes.Buffer{}})
+			Expect(err).To(MatchError(os.ErrInvalid))
+			Expect(delegate.Executions).To(HaveLen(1))
+		})
+
+		it("retries a build failing with a transient repository failure", func() {
+			delegate.Stdout = "[ERROR] Could not transfer artifact from central: status code: 503, reason phrase: " +
+				"Service Unavailable\n"
+			out := &bytes.Buffer{}
+
+			err := executor.Execute(effect.Execution{Args: []string{"package"}, Stdout: out})
+			Expect(err).To(MatchError(os.ErrInvalid))
+			Expect(delegate.Executions).To(HaveLen(3))
+			Expect(delegate.Executions[0].Args).To(Equal([]string{"package"}))
+			Expect(delegate.Executions[1].Args).To(Equal([]string{"--update-snapshots", "package"}))
+			Expect(delegate.Executions[2].Args).To(Equal([]string{"--update-snapshots", "package"}))
+			Expect(out.String()).To(ContainSubstring("status code: 503"))
+		})
+
+		it("does not add --update-snapshots if present", func() {
+			delegate.Stdout = "Connection reset"
+
+			Expect(executor.Execute(effect.Execution{Args: []string{"-U", "package"}, Stdout: &bytes.Buffer{}})).
+				To(MatchError(os.ErrInvalid))
+			Expect(delegate.Executions).To(HaveLen(3))
+			Expect(delegate.Executions[2].Args).To(Equal([]string{"-U", "package"}))
+		})
+	})
+}
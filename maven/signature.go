@@ -0,0 +1,117 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// SignatureSuffix is appended to the id of a dependency to find the dependency describing its detached signature.
+const SignatureSuffix = "-signature"
+
+// SignatureVerifier verifies a dependency artifact against its detached, armored PGP signature and a keyring.
+type SignatureVerifier struct {
+	DependencyCache libpak.DependencyCache
+	Keyring         openpgp.EntityList
+	Logger          bard.Logger
+	Signature       libpak.BuildpackDependency
+}
+
+// NewSignatureVerifier creates a new SignatureVerifier for dependency, resolving its signature as the dependency with
+// the same version and the id suffixed with SignatureSuffix, and reading the keyring from the KEYS file at keysPath.
+func NewSignatureVerifier(dependency libpak.BuildpackDependency, resolver libpak.DependencyResolver,
+	cache libpak.DependencyCache, keysPath string) (SignatureVerifier, error) {
+
+	signature, err := resolver.Resolve(dependency.ID+SignatureSuffix, dependency.Version)
+	if err != nil {
+		return SignatureVerifier{}, fmt.Errorf("unable to find signature of %s %s\n%w", dependency.ID, dependency.Version, err)
+	}
+
+	keyring, err := ReadKeys(keysPath)
+	if err != nil {
+		return SignatureVerifier{}, err
+	}
+
+	return SignatureVerifier{DependencyCache: cache, Keyring: keyring, Signature: signature}, nil
+}
+
+// ReadKeys reads the public keys of a KEYS file, a sequence of armored key blocks interleaved with free text.
+func ReadKeys(path string) (openpgp.EntityList, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer in.Close()
+
+	var (
+		keyring openpgp.EntityList
+		r       = bufio.NewReader(in)
+	)
+
+	for {
+		block, err := armor.Decode(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode %s\n%w", path, err)
+		}
+
+		entities, err := openpgp.ReadKeyRing(block.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read keys from %s\n%w", path, err)
+		}
+		keyring = append(keyring, entities...)
+	}
+
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("no keys found in %s", path)
+	}
+
+	return keyring, nil
+}
+
+// Verify verifies artifact against the signature, leaving artifact positioned at its start.
+func (s SignatureVerifier) Verify(artifact *os.File) error {
+	s.DependencyCache.Logger = s.Logger
+
+	signature, err := s.DependencyCache.Artifact(s.Signature)
+	if err != nil {
+		return fmt.Errorf("unable to get signature %s\n%w", s.Signature.URI, err)
+	}
+	defer signature.Close()
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(s.Keyring, artifact, signature, nil)
+	if err != nil {
+		return fmt.Errorf("unable to verify signature of %s\n%w", artifact.Name(), err)
+	}
+
+	s.Logger.Bodyf("Verified signature by key %X", signer.PrimaryKey.KeyId)
+
+	if _, err := artifact.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind %s\n%w", artifact.Name(), err)
+	}
+
+	return nil
+}
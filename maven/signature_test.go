@@ -0,0 +1,197 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testSignature(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cache  libpak.DependencyCache
+		entity *openpgp.Entity
+		path   string
+	)
+
+	newEntity := func(name string) *openpgp.Entity {
+		e, err := openpgp.NewEntity(name, "", fmt.Sprintf("%s@example.com", name), &packet.Config{RSABits: 1024})
+		Expect(err).NotTo(HaveOccurred())
+		return e
+	}
+
+	armoredKey := func(e *openpgp.Entity) string {
+		b := &bytes.Buffer{}
+		w, err := armor.Encode(b, openpgp.PublicKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		return b.String()
+	}
+
+	sign := func(e *openpgp.Entity, file string) {
+		in, err := os.Open(file)
+		Expect(err).NotTo(HaveOccurred())
+		defer in.Close()
+
+		out, err := os.Create(fmt.Sprintf("%s.asc", file))
+		Expect(err).NotTo(HaveOccurred())
+		defer out.Close()
+
+		Expect(openpgp.ArmoredDetachSign(out, e, in, nil)).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "signature")
+		Expect(err).NotTo(HaveOccurred())
+
+		cache = libpak.DependencyCache{DownloadPath: filepath.Join(path, "downloads")}
+		entity = newEntity("test-signer")
+
+		Expect(ioutil.WriteFile(filepath.Join(path, "KEYS"), []byte(strings.Join([]string{
+			"This file contains the keys used to sign releases.",
+			"pub   rsa1024 test-signer",
+			armoredKey(newEntity("test-other-signer")),
+			"pub   rsa1024 test-signer",
+			armoredKey(entity),
+		}, "\n")), 0644)).To(Succeed())
+
+		Expect(ioutil.WriteFile(filepath.Join(path, "test-artifact.tar.gz"), []byte("test-artifact"), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("reads all keys of a KEYS file", func() {
+		keyring, err := maven.ReadKeys(filepath.Join(path, "KEYS"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keyring).To(HaveLen(2))
+	})
+
+	it("fails to read a KEYS file without keys", func() {
+		Expect(ioutil.WriteFile(filepath.Join(path, "KEYS"), []byte("test-text"), 0644)).To(Succeed())
+
+		_, err := maven.ReadKeys(filepath.Join(path, "KEYS"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	it("resolves the signature of a dependency", func() {
+		resolver := libpak.DependencyResolver{
+			Dependencies: []libpak.BuildpackDependency{
+				{ID: "maven", Version: "3.8.6", Stacks: []string{"test-stack-id"}},
+				{ID: "maven-signature", Version: "3.8.5", URI: "test-uri-3.8.5", Stacks: []string{"test-stack-id"}},
+				{ID: "maven-signature", Version: "3.8.6", URI: "test-uri-3.8.6", Stacks: []string{"test-stack-id"}},
+			},
+			StackID: "test-stack-id",
+		}
+
+		v, err := maven.NewSignatureVerifier(resolver.Dependencies[0], resolver, cache, filepath.Join(path, "KEYS"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Signature.URI).To(Equal("test-uri-3.8.6"))
+		Expect(v.Keyring).To(HaveLen(2))
+	})
+
+	context("Verify", func() {
+		var verifier maven.SignatureVerifier
+
+		it.Before(func() {
+			keyring, err := maven.ReadKeys(filepath.Join(path, "KEYS"))
+			Expect(err).NotTo(HaveOccurred())
+
+			verifier = maven.SignatureVerifier{
+				DependencyCache: cache,
+				Keyring:         keyring,
+				Signature: libpak.BuildpackDependency{
+					URI: fmt.Sprintf("file://%s", filepath.Join(path, "test-artifact.tar.gz.asc")),
+				},
+			}
+		})
+
+		it("verifies a signed artifact", func() {
+			sign(entity, filepath.Join(path, "test-artifact.tar.gz"))
+
+			artifact, err := os.Open(filepath.Join(path, "test-artifact.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer artifact.Close()
+
+			Expect(verifier.Verify(artifact)).To(Succeed())
+
+			b, err := ioutil.ReadAll(artifact)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("test-artifact"))
+		})
+
+		it("rejects an artifact signed by an unknown key", func() {
+			sign(newEntity("test-unknown-signer"), filepath.Join(path, "test-artifact.tar.gz"))
+
+			artifact, err := os.Open(filepath.Join(path, "test-artifact.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer artifact.Close()
+
+			Expect(verifier.Verify(artifact)).To(MatchError(ContainSubstring("unable to verify signature")))
+		})
+
+		it("rejects a tampered artifact", func() {
+			sign(entity, filepath.Join(path, "test-artifact.tar.gz"))
+			Expect(ioutil.WriteFile(filepath.Join(path, "test-artifact.tar.gz"), []byte("test-tampered"), 0644)).To(Succeed())
+
+			artifact, err := os.Open(filepath.Join(path, "test-artifact.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer artifact.Close()
+
+			Expect(verifier.Verify(artifact)).To(MatchError(ContainSubstring("unable to verify signature")))
+		})
+
+		it("fails a distribution with an invalid signature", func() {
+			sign(newEntity("test-unknown-signer"), filepath.Join(path, "test-artifact.tar.gz"))
+			verifier.Signature.URI = fmt.Sprintf("file://%s", filepath.Join(path, "test-artifact.tar.gz.asc"))
+
+			d, _ := maven.NewDistribution(libpak.BuildpackDependency{
+				URI:    "https://localhost/stub-maven-distribution.tar.gz",
+				SHA256: "31ba45356e22aff670af88170f43ff82328e6f323c3ce891ba422bd1031e3308",
+			}, libpak.DependencyCache{CachePath: "testdata"})
+			d.Verifier = &verifier
+
+			layers := libcnb.Layers{Path: path}
+			layer, err := layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = d.Contribute(layer)
+			Expect(err).To(MatchError(ContainSubstring("unable to verify signature")))
+		})
+	})
+}
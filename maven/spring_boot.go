@@ -0,0 +1,221 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// SpringBootLayersIndex is the location, relative to the application root, of the layers index of an expanded Spring
+// Boot layered jar.
+const SpringBootLayersIndex = "BOOT-INF/layers.idx"
+
+// SpringBootLayerNames are the layers of the default Spring Boot layering that are split from the application into
+// their own image layers.  The application layer, and any custom layers, remain in the application root.
+var SpringBootLayerNames = []string{"dependencies", "spring-boot-loader", "snapshot-dependencies"}
+
+// LayersIndexEntry is a layer of a Spring Boot layers index, listing the files and directories that it contains.
+type LayersIndexEntry struct {
+	Name  string
+	Paths []string
+}
+
+// ReadLayersIndex reads the layers of a Spring Boot layers index, in order.
+func ReadLayersIndex(in io.Reader) ([]LayersIndexEntry, error) {
+	var entries []LayersIndexEntry
+
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		line := s.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "- ") {
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "- "), ":")
+			entries = append(entries, LayersIndexEntry{Name: strings.Trim(name, `"`)})
+		} else if path := strings.TrimSpace(line); strings.HasPrefix(path, "- ") && len(entries) > 0 {
+			entries[len(entries)-1].Paths = append(entries[len(entries)-1].Paths,
+				strings.Trim(strings.TrimPrefix(path, "- "), `"`))
+		} else {
+			return nil, fmt.Errorf("unable to parse layers index line %q", line)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read layers index\n%w", err)
+	}
+
+	return entries, nil
+}
+
+// LayerFiles returns the files, relative to the application root, that belong to the named layer.  As with Spring
+// Boot, each file belongs to the first layer listing it, or a directory containing it.
+func LayerFiles(applicationPath string, entries []LayersIndexEntry, name string) ([]string, error) {
+	var files []string
+
+	if err := filepath.Walk(applicationPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(applicationPath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, e := range entries {
+			if containsLayerFile(e.Paths, rel) {
+				if e.Name == name {
+					files = append(files, rel)
+				}
+				return nil
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to walk %s\n%w", applicationPath, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func containsLayerFile(paths []string, file string) bool {
+	for _, p := range paths {
+		if p == file || (strings.HasSuffix(p, "/") && strings.HasPrefix(file, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SpringBootLayer moves the files of a layer of an expanded Spring Boot layered jar from the application root to an
+// image layer, linking them back so that the application is unchanged.  The layer is cached, and reused while its files
+// do not change.
+type SpringBootLayer struct {
+	ApplicationPath string
+	Index           string
+	Logger          bard.Logger
+}
+
+func (s SpringBootLayer) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	in, err := os.Open(filepath.Join(s.ApplicationPath, SpringBootLayersIndex))
+	if os.IsNotExist(err) {
+		return layer, nil
+	} else if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", SpringBootLayersIndex, err)
+	}
+	defer in.Close()
+
+	entries, err := ReadLayersIndex(in)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to read %s\n%w", SpringBootLayersIndex, err)
+	}
+
+	files, err := LayerFiles(s.ApplicationPath, entries, s.Index)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to find files of layer %s\n%w", s.Index, err)
+	}
+	if len(files) == 0 {
+		return layer, nil
+	}
+
+	hash := sha256.New()
+	for _, f := range files {
+		if err := s.digest(hash, f); err != nil {
+			return libcnb.Layer{}, err
+		}
+	}
+
+	// the layer is cached so that, when reused, its files are restored for the buildpacks reading the application
+	lc := libpak.NewLayerContributor(fmt.Sprintf("Spring Boot %s", s.Index),
+		map[string]interface{}{"sha256": hex.EncodeToString(hash.Sum(nil))}, libcnb.LayerTypes{Cache: true, Launch: true})
+	lc.Logger = s.Logger
+
+	layer, err = lc.Contribute(layer, func() (libcnb.Layer, error) {
+		for _, f := range files {
+			in, err := os.Open(filepath.Join(s.ApplicationPath, f))
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", f, err)
+			}
+
+			err = sherpa.CopyFile(in, filepath.Join(layer.Path, f))
+			in.Close()
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to copy %s to %s\n%w", f, layer.Path, err)
+			}
+		}
+
+		return layer, nil
+	})
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	for _, f := range files {
+		file := filepath.Join(s.ApplicationPath, f)
+		if err := os.Remove(file); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", file, err)
+		}
+		if err := os.Symlink(filepath.Join(layer.Path, f), file); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to link %s\n%w", file, err)
+		}
+	}
+
+	s.Logger.Bodyf("Linked %d files of layer %s", len(files), s.Index)
+	return layer, nil
+}
+
+func (s SpringBootLayer) digest(hash io.Writer, file string) error {
+	in, err := os.Open(filepath.Join(s.ApplicationPath, file))
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	if _, err := fmt.Fprintln(hash, file); err != nil {
+		return fmt.Errorf("unable to hash %s\n%w", file, err)
+	}
+	if _, err := io.Copy(hash, in); err != nil {
+		return fmt.Errorf("unable to hash %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+func (s SpringBootLayer) Name() string {
+	return fmt.Sprintf("spring-boot-%s", s.Index)
+}
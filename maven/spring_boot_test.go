@@ -0,0 +1,176 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testSpringBoot(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx libcnb.BuildContext
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Application.Path, err = ioutil.TempDir("", "spring-boot-application")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx.Layers.Path, err = ioutil.TempDir("", "spring-boot-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, f := range []string{
+			"BOOT-INF/classes/Application.class",
+			"BOOT-INF/lib/a-1.0.0.jar",
+			"BOOT-INF/lib/b-1.0.0-SNAPSHOT.jar",
+			"org/springframework/boot/loader/JarLauncher.class",
+		} {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, filepath.Dir(f)), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, f), []byte(f), 0644)).To(Succeed())
+		}
+
+		Expect(ioutil.WriteFile(filepath.Join(ctx.Application.Path, "BOOT-INF", "layers.idx"), []byte(`- "dependencies":
+  - "BOOT-INF/lib/a-1.0.0.jar"
+- "spring-boot-loader":
+  - "org/"
+- "snapshot-dependencies":
+  - "BOOT-INF/lib/b-1.0.0-SNAPSHOT.jar"
+- "application":
+  - "BOOT-INF/classes/"
+  - "BOOT-INF/layers.idx"
+`), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("reads the layers index", func() {
+		Expect(maven.ReadLayersIndex(strings.NewReader(`- "dependencies":
+  - "BOOT-INF/lib/"
+- "snapshot-dependencies":
+- "application":
+  - "BOOT-INF/classes/"
+`))).To(Equal([]maven.LayersIndexEntry{
+			{Name: "dependencies", Paths: []string{"BOOT-INF/lib/"}},
+			{Name: "snapshot-dependencies"},
+			{Name: "application", Paths: []string{"BOOT-INF/classes/"}},
+		}))
+	})
+
+	it("assigns files to the first layer listing them", func() {
+		entries := []maven.LayersIndexEntry{
+			{Name: "snapshot-dependencies", Paths: []string{"BOOT-INF/lib/b-1.0.0-SNAPSHOT.jar"}},
+			{Name: "dependencies", Paths: []string{"BOOT-INF/lib/"}},
+		}
+
+		Expect(maven.LayerFiles(ctx.Application.Path, entries, "dependencies")).
+			To(Equal([]string{"BOOT-INF/lib/a-1.0.0.jar"}))
+	})
+
+	it("moves and links the files of a layer", func() {
+		layer, err := ctx.Layers.Layer("spring-boot-dependencies")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = maven.SpringBootLayer{ApplicationPath: ctx.Application.Path, Index: "dependencies"}.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.LayerTypes.Cache).To(BeTrue())
+		Expect(layer.LayerTypes.Launch).To(BeTrue())
+		Expect(layer.Metadata).To(HaveKey("sha256"))
+		Expect(filepath.Join(layer.Path, "BOOT-INF", "lib", "a-1.0.0.jar")).To(BeARegularFile())
+
+		file := filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "a-1.0.0.jar")
+		Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "BOOT-INF", "lib", "a-1.0.0.jar")))
+		Expect(ioutil.ReadFile(file)).To(Equal([]byte("BOOT-INF/lib/a-1.0.0.jar")))
+		Expect(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "b-1.0.0-SNAPSHOT.jar")).To(BeARegularFile())
+	})
+
+	context("the layer is reused", func() {
+		var (
+			file  string
+			layer libcnb.Layer
+		)
+
+		it.Before(func() {
+			var err error
+
+			layer, err = ctx.Layers.Layer("spring-boot-dependencies")
+			Expect(err).NotTo(HaveOccurred())
+
+			contributed, err := maven.SpringBootLayer{ApplicationPath: ctx.Application.Path, Index: "dependencies"}.
+				Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			// the next build has the metadata of the layer and expands the same application
+			layer.Metadata = contributed.Metadata
+			Expect(ioutil.WriteFile(layer.Path+".toml", []byte{}, 0644)).To(Succeed())
+
+			file = filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "a-1.0.0.jar")
+			Expect(os.Remove(file)).To(Succeed())
+			Expect(ioutil.WriteFile(file, []byte("BOOT-INF/lib/a-1.0.0.jar"), 0644)).To(Succeed())
+		})
+
+		it("links the files restored from the cache", func() {
+			layer, err := maven.SpringBootLayer{ApplicationPath: ctx.Application.Path, Index: "dependencies"}.
+				Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "BOOT-INF", "lib", "a-1.0.0.jar")))
+			Expect(ioutil.ReadFile(file)).To(Equal([]byte("BOOT-INF/lib/a-1.0.0.jar")))
+		})
+
+		it("contributes the files again if they were not restored", func() {
+			Expect(os.RemoveAll(layer.Path)).To(Succeed())
+			Expect(os.MkdirAll(layer.Path, 0755)).To(Succeed())
+
+			layer, err := maven.SpringBootLayer{ApplicationPath: ctx.Application.Path, Index: "dependencies"}.
+				Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "BOOT-INF", "lib", "a-1.0.0.jar")))
+			Expect(ioutil.ReadFile(file)).To(Equal([]byte("BOOT-INF/lib/a-1.0.0.jar")))
+		})
+	})
+
+	it("does not contribute without a layers index", func() {
+		Expect(os.Remove(filepath.Join(ctx.Application.Path, "BOOT-INF", "layers.idx"))).To(Succeed())
+
+		layer, err := ctx.Layers.Layer("spring-boot-dependencies")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = maven.SpringBootLayer{ApplicationPath: ctx.Application.Path, Index: "dependencies"}.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.LayerTypes.Launch).To(BeFalse())
+		Expect(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "a-1.0.0.jar")).To(BeARegularFile())
+	})
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command stub-maven stands in for mvn in integration tests.  It resolves the dependencies declared by the POM from
+// its repository, or the mirror of the repository in the settings, into the local repository, honouring server
+// credentials and offline mode, and stages the resolved jars in target/app/lib as the application artifact.
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type POM struct {
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"dependencies>dependency"`
+	Repositories []Repository `xml:"repositories>repository"`
+}
+
+type Repository struct {
+	ID  string `xml:"id"`
+	URL string `xml:"url"`
+}
+
+type Settings struct {
+	Mirrors []struct {
+		ID       string `xml:"id"`
+		MirrorOf string `xml:"mirrorOf"`
+		URL      string `xml:"url"`
+	} `xml:"mirrors>mirror"`
+	Servers []struct {
+		ID       string `xml:"id"`
+		Username string `xml:"username"`
+		Password string `xml:"password"`
+	} `xml:"servers>server"`
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	file, local, settingsFile, offline := "pom.xml", "", "", false
+	for i, a := range args {
+		switch {
+		case a == "--file" && i+1 < len(args):
+			file = args[i+1]
+		case strings.HasPrefix(a, "-Dmaven.repo.local="):
+			local = strings.TrimPrefix(a, "-Dmaven.repo.local=")
+		case strings.HasPrefix(a, "--settings="):
+			settingsFile = strings.TrimPrefix(a, "--settings=")
+		case a == "-o" || a == "--offline":
+			offline = true
+		}
+	}
+
+	if local == "" {
+		return fmt.Errorf("the local repository is not set")
+	}
+
+	var pom POM
+	if err := decode(file, &pom); err != nil {
+		return err
+	}
+
+	var settings Settings
+	if settingsFile != "" {
+		if err := decode(settingsFile, &settings); err != nil {
+			return err
+		}
+	}
+
+	repository := Repository{ID: "central", URL: "https://repo.maven.apache.org/maven2"}
+	if len(pom.Repositories) > 0 {
+		repository = pom.Repositories[0]
+	}
+	for _, m := range settings.Mirrors {
+		if m.MirrorOf == "*" || m.MirrorOf == repository.ID {
+			repository = Repository{ID: m.ID, URL: m.URL}
+			break
+		}
+	}
+
+	for _, d := range pom.Dependencies {
+		name := fmt.Sprintf("%s-%s.jar", d.ArtifactID, d.Version)
+		path := filepath.Join(strings.ReplaceAll(d.GroupID, ".", "/"), d.ArtifactID, d.Version, name)
+		artifact := filepath.Join(local, path)
+
+		if _, err := os.Stat(artifact); os.IsNotExist(err) {
+			if offline {
+				return fmt.Errorf("cannot access %s (%s) in offline mode and the artifact %s:%s:%s has not been "+
+					"downloaded from it before", repository.ID, repository.URL, d.GroupID, d.ArtifactID, d.Version)
+			}
+
+			if err := download(repository, settings, path, artifact); err != nil {
+				return err
+			}
+		}
+
+		if err := copyFile(artifact, filepath.Join("target", "app", "lib", name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decode(file string, v interface{}) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return xml.NewDecoder(in).Decode(v)
+}
+
+func download(repository Repository, settings Settings, path string, artifact string) error {
+	uri := fmt.Sprintf("%s/%s", strings.TrimSuffix(repository.URL, "/"), filepath.ToSlash(path))
+	fmt.Printf("Downloading from %s: %s\n", repository.ID, uri)
+
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range settings.Servers {
+		if s.ID == repository.ID {
+			req.SetBasicAuth(s.Username, s.Password)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not transfer artifact from %s\n%w", repository.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not transfer artifact from %s (%s): status code: %d, reason phrase: %s",
+			repository.ID, repository.URL, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(artifact), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(artifact)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+func copyFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+// TimingReportFile is the name of the timing report in the Timing layer.
+const TimingReportFile = "timing.json"
+
+var (
+	// ansiEscape matches the color codes of Maven output on a terminal.
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	// executionStart matches the header that Maven logs before a plugin execution, e.g.
+	// --- maven-compiler-plugin:3.8.1:compile (default-compile) @ demo ---
+	executionStart = regexp.MustCompile(`--- ([^:\s]+):([^:\s]+):(\S+) \(([^)]*)\) @ (\S+) ---`)
+
+	// executionEnd matches the output of Maven that ends a plugin execution without starting another: the header of a
+	// module, e.g. ---< com.example:demo >---, and the separator before the summary of the build.
+	executionEnd = regexp.MustCompile(`^\[INFO\] (-+< \S+ >-+|-{72})$`)
+)
+
+// ExecutionTiming is the duration of a plugin execution of a module.
+type ExecutionTiming struct {
+	Module       string `json:"module"`
+	Plugin       string `json:"plugin"`
+	Version      string `json:"version"`
+	Goal         string `json:"goal"`
+	Execution    string `json:"execution"`
+	Milliseconds int64  `json:"milliseconds"`
+}
+
+// ModuleTiming is the total duration of the plugin executions of a module.
+type ModuleTiming struct {
+	Module       string `json:"module"`
+	Milliseconds int64  `json:"milliseconds"`
+}
+
+// TimingReport is the duration of the plugin executions of a build, in the order they ran, and of its modules.
+type TimingReport struct {
+	Executions []ExecutionTiming `json:"executions"`
+	Modules    []ModuleTiming    `json:"modules"`
+}
+
+// Timing contributes an empty build layer that the TimingExecutor writes the timing report of the build to.
+type Timing struct{}
+
+func (Timing) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.RemoveAll(layer.Path); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", layer.Path, err)
+	}
+
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer directory %s\n%w", layer.Path, err)
+	}
+
+	layer.LayerTypes.Build = true
+	return layer, nil
+}
+
+func (Timing) Name() string {
+	return "timing"
+}
+
+// TimingExecutor is an effect.Executor that times the plugin executions of a build from the headers that Maven logs
+// before each of them.  Once the build has completed, successfully or not, it logs the duration of the executions and
+// writes them to Report as JSON.  With a parallel build, where the output of modules interleaves, durations are
+// approximate.
+type TimingExecutor struct {
+	Clock    func() time.Time
+	Delegate effect.Executor
+	Logger   bard.Logger
+	Report   string
+}
+
+func (t TimingExecutor) Execute(execution effect.Execution) error {
+	clock := t.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	recorder := &timingRecorder{Clock: clock}
+	execution.Stdout = tee(execution.Stdout, recorder)
+
+	err := t.Delegate.Execute(execution)
+
+	report := recorder.Report()
+	t.log(report)
+
+	b, jsonErr := json.MarshalIndent(report, "", "  ")
+	if jsonErr != nil {
+		return fmt.Errorf("unable to encode timing report\n%w", jsonErr)
+	}
+	if writeErr := ioutil.WriteFile(t.Report, b, 0644); writeErr != nil {
+		return fmt.Errorf("unable to write %s\n%w", t.Report, writeErr)
+	}
+
+	return err
+}
+
+func (t TimingExecutor) log(report TimingReport) {
+	if len(report.Executions) == 0 {
+		t.Logger.Body("No plugin executions timed")
+		return
+	}
+
+	t.Logger.Header("Build timing")
+	for _, m := range report.Modules {
+		t.Logger.Bodyf("%10s  %s", duration(m.Milliseconds), m.Module)
+
+		for _, e := range report.Executions {
+			if e.Module == m.Module {
+				t.Logger.Bodyf("%10s    %s:%s:%s (%s)", duration(e.Milliseconds), e.Plugin, e.Version, e.Goal, e.Execution)
+			}
+		}
+	}
+	t.Logger.Bodyf("Writing timing report %s", t.Report)
+}
+
+func duration(milliseconds int64) string {
+	return (time.Duration(milliseconds) * time.Millisecond).String()
+}
+
+// timingRecorder is an io.Writer that times plugin executions line by line.
+type timingRecorder struct {
+	Clock func() time.Time
+
+	current    *ExecutionTiming
+	executions []ExecutionTiming
+	line       []byte
+	start      time.Time
+}
+
+func (t *timingRecorder) Write(p []byte) (int, error) {
+	t.line = append(t.line, p...)
+
+	for {
+		i := bytes.IndexByte(t.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		t.record(string(t.line[:i]))
+		t.line = t.line[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Report ends the current plugin execution, and returns the timing of the executions and of their modules.
+func (t *timingRecorder) Report() TimingReport {
+	if len(t.line) > 0 {
+		t.record(string(t.line))
+		t.line = nil
+	}
+	t.end()
+
+	report := TimingReport{Executions: []ExecutionTiming{}, Modules: []ModuleTiming{}}
+
+	modules := map[string]int{}
+	for _, e := range t.executions {
+		report.Executions = append(report.Executions, e)
+
+		i, ok := modules[e.Module]
+		if !ok {
+			i = len(report.Modules)
+			modules[e.Module] = i
+			report.Modules = append(report.Modules, ModuleTiming{Module: e.Module})
+		}
+		report.Modules[i].Milliseconds += e.Milliseconds
+	}
+
+	return report
+}
+
+func (t *timingRecorder) record(line string) {
+	line = strings.TrimRight(ansiEscape.ReplaceAllString(line, ""), "\r")
+
+	if m := executionStart.FindStringSubmatch(line); m != nil {
+		t.end()
+		t.current = &ExecutionTiming{Module: m[5], Plugin: m[1], Version: m[2], Goal: m[3], Execution: m[4]}
+		t.start = t.Clock()
+	} else if executionEnd.MatchString(line) {
+		t.end()
+	}
+}
+
+func (t *timingRecorder) end() {
+	if t.current == nil {
+		return
+	}
+
+	t.current.Milliseconds = t.Clock().Sub(t.start).Milliseconds()
+	t.executions = append(t.executions, *t.current)
+	t.current = nil
+}
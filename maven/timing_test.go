@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testTiming(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "timing")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("contributes a build layer", func() {
+		layer, err := maven.Timing{}.Contribute(libcnb.Layer{Path: filepath.Join(path, "timing")})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.LayerTypes.Build).To(BeTrue())
+		Expect(layer.Path).To(BeADirectory())
+	})
+
+	context("TimingExecutor", func() {
+		var (
+			buf      *bytes.Buffer
+			executor maven.TimingExecutor
+			now      time.Time
+		)
+
+		it.Before(func() {
+			buf = &bytes.Buffer{}
+			now = time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+			// each call of the clock, at the start and end of each execution, advances it by one second
+			executor = maven.TimingExecutor{
+				Clock: func() time.Time {
+					now = now.Add(time.Second)
+					return now
+				},
+				Logger: bard.NewLogger(buf),
+				Report: filepath.Join(path, "timing.json"),
+			}
+		})
+
+		it("times the plugin executions of each module", func() {
+			executor.Delegate = &FakeExecutor{Stdout: `[INFO] Scanning for projects...
+[INFO] --------------------------< com.example:api >---------------------------
+[INFO] --- maven-resources-plugin:3.2.0:resources (default-resources) @ api ---
+[INFO] Copying 1 resource
+[INFO] --- maven-compiler-plugin:3.8.1:compile (default-compile) @ api ---
+[INFO] Compiling 3 source files
+[INFO]
+[INFO] --------------------------< com.example:web >---------------------------
+[INFO] --- ` + "\x1b[1;32mfrontend:1.12.1:npm\x1b[m (npm install)" + ` @ web ---
+[INFO] ------------------------------------------------------------------------
+[INFO] BUILD SUCCESS
+`}
+
+			Expect(executor.Execute(effect.Execution{Stdout: &bytes.Buffer{}})).To(Succeed())
+
+			b, err := ioutil.ReadFile(filepath.Join(path, "timing.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var report maven.TimingReport
+			Expect(json.Unmarshal(b, &report)).To(Succeed())
+			Expect(report).To(Equal(maven.TimingReport{
+				Executions: []maven.ExecutionTiming{
+					{
+						Module:       "api",
+						Plugin:       "maven-resources-plugin",
+						Version:      "3.2.0",
+						Goal:         "resources",
+						Execution:    "default-resources",
+						Milliseconds: 1000,
+					},
+					{
+						Module:       "api",
+						Plugin:       "maven-compiler-plugin",
+						Version:      "3.8.1",
+						Goal:         "compile",
+						Execution:    "default-compile",
+						Milliseconds: 1000,
+					},
+					{
+						Module:       "web",
+						Plugin:       "frontend",
+						Version:      "1.12.1",
+						Goal:         "npm",
+						Execution:    "npm install",
+						Milliseconds: 1000,
+					},
+				},
+				Modules: []maven.ModuleTiming{
+					{Module: "api", Milliseconds: 2000},
+					{Module: "web", Milliseconds: 1000},
+				},
+			}))
+
+			Expect(buf.String()).To(ContainSubstring("Build timing"))
+			Expect(buf.String()).To(ContainSubstring("2s  api"))
+			Expect(buf.String()).To(ContainSubstring("1s    frontend:1.12.1:npm (npm install)"))
+		})
+
+		it("reports the executions of a failed build", func() {
+			executor.Delegate = &FakeExecutor{
+				Stdout: "[INFO] --- maven-compiler-plugin:3.8.1:compile (default-compile) @ api ---",
+				Err:    os.ErrInvalid,
+			}
+
+			Expect(executor.Execute(effect.Execution{})).To(MatchError(os.ErrInvalid))
+
+			b, err := ioutil.ReadFile(filepath.Join(path, "timing.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var report maven.TimingReport
+			Expect(json.Unmarshal(b, &report)).To(Succeed())
+			Expect(report.Executions).To(HaveLen(1))
+			Expect(report.Executions[0].Goal).To(Equal("compile"))
+		})
+	})
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// MavenUserProperties is the project-level properties file, relative to the application root, introduced by Maven 4.
+var MavenUserProperties = filepath.Join(".mvn", "maven-user.properties")
+
+// MavenWrapperProperties is the configuration of the Maven Wrapper, relative to the directory of mvnw.
+var MavenWrapperProperties = filepath.Join(".mvn", "wrapper", "maven-wrapper.properties")
+
+// wrapperDistribution matches the version of the Maven distribution in the distributionUrl of the Maven Wrapper, e.g.
+// distributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/3.8.6/apache-maven-3.8.6-bin.zip
+var wrapperDistribution = regexp.MustCompile(`(?m)^\s*distributionUrl\s*[=:].*/apache-maven-([^/]+)-bin\.(zip|tar\.gz)\s*$`)
+
+// MavenVersion returns the version of Maven to build the application with: $BP_MAVEN_VERSION if set, otherwise 4 if
+// the application requires Maven 4, otherwise 3.
+func MavenVersion(cr libpak.ConfigurationResolver, applicationPath string, pom POM) string {
+	if v, ok := cr.Resolve("BP_MAVEN_VERSION"); ok {
+		return v
+	}
+
+	if RequiresMaven4(applicationPath, pom) {
+		return "4"
+	}
+
+	return "3"
+}
+
+// MavenVersionConstraint returns the constraint resolving a Maven dependency for version, which may be a major or a
+// more specific version.
+func MavenVersionConstraint(version string) string {
+	if strings.Count(version, ".") < 2 {
+		return version + ".*"
+	}
+	return version
+}
+
+// IsMaven4 returns whether version is a Maven 4 version.
+func IsMaven4(version string) bool {
+	return version == "4" || strings.HasPrefix(version, "4.")
+}
+
+// IsMavenBefore returns whether version is known to be older than minimum.
+func IsMavenBefore(version string, minimum string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return v.LessThan(semver.MustParse(minimum))
+}
+
+// MvndMavenVersion returns the version of Maven embedded by the Maven Daemon version: Maven 3.8 before mvnd 1, Maven
+// 3.9 in mvnd 1 and Maven 4 in mvnd 2.  Returns false if the version cannot be determined.
+func MvndMavenVersion(version string) (string, bool) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "", false
+	}
+
+	switch v.Major() {
+	case 0:
+		return "3.8", true
+	case 1:
+		return "3.9", true
+	case 2:
+		return "4.0", true
+	default:
+		return "", false
+	}
+}
+
+// RequiresMaven4 returns whether the application uses a feature introduced by Maven 4: a POM model newer than 4.0.0
+// or a project-level .mvn/maven-user.properties.
+func RequiresMaven4(applicationPath string, pom POM) bool {
+	if v, err := semver.NewVersion(pom.ModelVersion); err == nil && v.GreaterThan(semver.MustParse("4.0.0")) {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(applicationPath, MavenUserProperties))
+	return err == nil
+}
+
+// WrapperMavenVersion returns the version of Maven that the Maven Wrapper at wrapper downloads.  Returns false if the
+// version cannot be determined.
+func WrapperMavenVersion(wrapper string) (string, bool) {
+	b, err := ioutil.ReadFile(filepath.Join(filepath.Dir(wrapper), MavenWrapperProperties))
+	if err != nil {
+		return "", false
+	}
+
+	m := wrapperDistribution.FindSubmatch(b)
+	if m == nil {
+		return "", false
+	}
+	return string(m[1]), true
+}
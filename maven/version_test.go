@@ -0,0 +1,127 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maven_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/maven/v6/maven"
+)
+
+func testVersion(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = ioutil.TempDir("", "version")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("defaults to Maven 3", func() {
+		Expect(maven.MavenVersion(libpak.ConfigurationResolver{}, path, maven.POM{ModelVersion: "4.0.0"})).To(Equal("3"))
+	})
+
+	it("requires Maven 4 for a newer model", func() {
+		Expect(maven.MavenVersion(libpak.ConfigurationResolver{}, path, maven.POM{ModelVersion: "4.1.0"})).To(Equal("4"))
+	})
+
+	it("requires Maven 4 for .mvn/maven-user.properties", func() {
+		Expect(os.MkdirAll(filepath.Join(path, ".mvn"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(path, ".mvn", "maven-user.properties"), []byte{}, 0644)).To(Succeed())
+
+		Expect(maven.MavenVersion(libpak.ConfigurationResolver{}, path, maven.POM{})).To(Equal("4"))
+	})
+
+	context("$BP_MAVEN_VERSION", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_MAVEN_VERSION", "3.9")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_MAVEN_VERSION")).To(Succeed())
+		})
+
+		it("takes precedence", func() {
+			Expect(maven.MavenVersion(libpak.ConfigurationResolver{}, path, maven.POM{ModelVersion: "4.1.0"})).To(Equal("3.9"))
+		})
+	})
+
+	it("creates version constraints", func() {
+		Expect(maven.MavenVersionConstraint("4")).To(Equal("4.*"))
+		Expect(maven.MavenVersionConstraint("3.9")).To(Equal("3.9.*"))
+		Expect(maven.MavenVersionConstraint("3.8.6")).To(Equal("3.8.6"))
+	})
+
+	it("identifies Maven 4", func() {
+		Expect(maven.IsMaven4("4")).To(BeTrue())
+		Expect(maven.IsMaven4("4.0.0-rc-2")).To(BeTrue())
+		Expect(maven.IsMaven4("3.9")).To(BeFalse())
+	})
+
+	it("identifies the Maven embedded by the Maven Daemon", func() {
+		for mvnd, expected := range map[string]string{"0.8.0": "3.8", "1.0.2": "3.9", "2.0.0-rc-3": "4.0"} {
+			v, ok := maven.MvndMavenVersion(mvnd)
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal(expected))
+		}
+
+		_, ok := maven.MvndMavenVersion("test-version")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("compares known versions", func() {
+		Expect(maven.IsMavenBefore("3.8.6", "3.9.0")).To(BeTrue())
+		Expect(maven.IsMavenBefore("3.9.6", "3.9.0")).To(BeFalse())
+		Expect(maven.IsMavenBefore("4.0.0-rc-2", "3.9.0")).To(BeFalse())
+		Expect(maven.IsMavenBefore("", "3.9.0")).To(BeFalse())
+	})
+
+	context("WrapperMavenVersion", func() {
+		it("returns the version of the wrapper distribution", func() {
+			Expect(os.MkdirAll(filepath.Join(path, ".mvn", "wrapper"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(path, ".mvn", "wrapper", "maven-wrapper.properties"), []byte(
+				"wrapperUrl=https://repo.maven.apache.org/maven2/org/apache/maven/wrapper/maven-wrapper/3.1.1/maven-wrapper-3.1.1.jar\n"+
+					"distributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/4.0.0-alpha-7/apache-maven-4.0.0-alpha-7-bin.zip\n"),
+				0644)).To(Succeed())
+
+			version, ok := maven.WrapperMavenVersion(filepath.Join(path, "mvnw"))
+			Expect(ok).To(BeTrue())
+			Expect(version).To(Equal("4.0.0-alpha-7"))
+		})
+
+		it("returns false without wrapper properties", func() {
+			_, ok := maven.WrapperMavenVersion(filepath.Join(path, "mvnw"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+}
@@ -12,5 +12,20 @@ if [ "${COMPRESS:-none}" != "none" ]; then
   $COMPRESS bin/main
 fi
 
+# KEYS is the trust anchor of signature verification, only packaged when it matches the reviewed checksum.  Without a
+# reviewed checksum an empty KEYS is packaged, with which $BP_MAVEN_VERIFY_SIGNATURES fails as no keys are found.
+if [ -f scripts/KEYS.sha256 ]; then
+  if [ ! -f KEYS ]; then
+    curl --silent --show-error --fail --location --output KEYS https://downloads.apache.org/maven/KEYS
+  fi
+  sha256sum --check --quiet scripts/KEYS.sha256
+elif [ -s KEYS ]; then
+  echo "KEYS has not been reviewed, run scripts/update-keys.sh" >&2
+  exit 1
+else
+  echo "WARNING: scripts/KEYS.sha256 is missing, packaging no KEYS to verify signatures with" >&2
+  : > KEYS
+fi
+
 ln -fs main bin/build
 ln -fs main bin/detect
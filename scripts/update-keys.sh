@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+# Downloads the Apache Maven KEYS, pinning its checksum in scripts/KEYS.sha256 once the keys have been reviewed, and
+# sets the checksums of the signature dependencies of buildpack.toml.
+
+curl --silent --show-error --fail --location --output KEYS https://downloads.apache.org/maven/KEYS
+
+gpg --show-keys --with-fingerprint KEYS
+read -r -p "Trust these keys? [y/N] " answer
+if [ "${answer}" != "y" ]; then
+  rm KEYS
+  exit 1
+fi
+
+sha256sum KEYS > scripts/KEYS.sha256
+
+# the signature dependencies are cached, and downloaded once, only with a checksum
+for uri in $(grep --only-matching --extended-regexp 'https://[^"]+\.asc' buildpack.toml); do
+  sha256=$(curl --silent --show-error --fail --location "${uri}" | sha256sum | cut -d ' ' -f 1)
+  awk -v entry="uri = \"${uri}\"" -v sha256="${sha256}" '
+    { line = $0; sub(/^ +/, "", line) }
+    line == entry { indent = $0; sub(/[^ ].*$/, "", indent) }
+    NR > 1 && !(line == entry && previous ~ /^ *sha256 = /) { print previous }
+    line == entry { print indent "sha256 = \"" sha256 "\"" }
+    { previous = $0 }
+    END { if (NR > 0) print previous }
+  ' buildpack.toml > buildpack.toml.tmp
+  mv buildpack.toml.tmp buildpack.toml
+  echo "${uri}: sha256 = \"${sha256}\""
+done